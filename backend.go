@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- Backends ---
+//
+// A backend is the toolchain that type-checks a koan, emits its JavaScript
+// and runs the tests:
+//
+//	tsc   TypeScript's tsc, through the compiler worker, and node (the default)
+//	tsgo  the native TypeScript compiler preview, and node
+//	deno  deno check, deno bundle and deno run
+//	bun   tsc, run by bun, and bun
+//
+// Whichever it is, diagnostics come back in tsc's --pretty false format and
+// the tests run in runner.mjs. Pick one with -backend or "backend" in
+// ~/.ts-koans/config.json.
+
+type backend interface {
+	// Check says what is missing if the backend's tools aren't installed.
+	Check() error
+	// Versions describes the tools the backend uses, for the debug panel.
+	Versions() []string
+	// Compile type-checks the project at tsconfigPath, from dir, and unless
+	// it sets noEmit writes typecheck.js and the koan's other files' JS next
+	// to it. The output is tsc-style diagnostics, and err is non-nil if
+	// there were errors.
+	Compile(ctx context.Context, tsconfigPath, dir string) (stdout, stderr string, err error)
+	// TestCommand returns the command that runs script from dir.
+	TestCommand(ctx context.Context, dir, script string) *exec.Cmd
+	// EventPipe gives a command from TestCommand its channel for runner
+	// events; see events.go.
+	EventPipe(cmd *exec.Cmd) (events io.ReadCloser, started, exited func(), err error)
+}
+
+var backendNames = []string{"tsc", "tsgo", "deno", "bun"}
+
+// activeBackend is the session's backend.
+var activeBackend backend = tscBackend{}
+
+// nodeMissing is set when Node.js isn't installed but tsgo is, which can
+// still type-check: only type-only koans can be solved.
+var nodeMissing bool
+
+type backendKey struct{}
+
+// withBackend returns a context whose runs use b rather than the session's
+// backend.
+func withBackend(ctx context.Context, b backend) context.Context {
+	return context.WithValue(ctx, backendKey{}, b)
+}
+
+// backendFor returns the backend runs under ctx use.
+func backendFor(ctx context.Context) backend {
+	if b, ok := ctx.Value(backendKey{}).(backend); ok {
+		return b
+	}
+	return activeBackend
+}
+
+// runsESM reports whether b can run ES module koans. runner.mjs links them
+// with node's vm.SourceTextModule, and Deno and Bun bundle the koan as
+// CommonJS.
+func runsESM(b backend) bool {
+	switch b.(type) {
+	case denoBackend, bunBackend:
+		return false
+	}
+	return true
+}
+
+// errESMUnsupported is returned for an ES module koan on a backend that
+// can't run it.
+var errESMUnsupported = errors.New("ES module koans need the tsc or tsgo backend")
+
+// backendNamed returns the backend called name.
+func backendNamed(name string) (backend, error) {
+	switch name {
+	case "", "tsc", "node":
+		return tscBackend{}, nil
+	case "tsgo":
+		return tsgoBackend{}, nil
+	case "deno":
+		return denoBackend{}, nil
+	case "bun":
+		return bunBackend{}, nil
+	}
+	return nil, fmt.Errorf("unknown backend %q (choose from %s)", name, strings.Join(backendNames, ", "))
+}
+
+// toolVersion returns the first line of `name args...`, e.g. "v20.19.5"
+// for node --version, or why there is none.
+func toolVersion(name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "not found"
+	}
+	first, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return first
+}
+
+// runTool runs cmd and returns what it printed.
+func runTool(cmd *exec.Cmd) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// nodeTestCommand runs script with node, in the sandbox (see sandbox.go).
+func nodeTestCommand(ctx context.Context, dir, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "node", nodeSandboxArgs(dir, script)...)
+}
+
+// errNodeMissing is returned by Check when node isn't installed.
+var errNodeMissing = errors.New("Node.js not found in PATH. Please install Node.js (https://nodejs.org/) and try again.")
+
+// tscBackend is TypeScript's own tsc, and node.
+type tscBackend struct{}
+
+func (tscBackend) Check() error {
+	if !nodeAvailable() {
+		return errNodeMissing
+	}
+	if !tscAvailable() {
+		return errors.New("tsc not found in PATH. Please install with `npm install -g typescript`.")
+	}
+	return nil
+}
+
+func (tscBackend) Versions() []string {
+	tsc := "tsc " + toolVersion("tsc", "--version")
+	if bundled := os.Getenv("TSKOANS_TSC"); bundled != "" {
+		tsc = "tsc " + toolVersion("node", bundled, "--version") + " (bundled)"
+	}
+	return []string{"node " + toolVersion("node", "--version"), tsc}
+}
+
+// Compile uses the session's compiler worker, or failing that runs tsc.
+func (tscBackend) Compile(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	if w := compilerWorker.Load(); w != nil {
+		stdout, err := w.compile(ctx, tsconfigPath)
+		if ctx.Err() != nil {
+			return "", "", ctx.Err()
+		}
+		if !errors.Is(err, errTSWorkerFailed) {
+			return stdout, "", err
+		}
+	}
+	cmd := tscCommand(ctx, tsconfigPath)
+	cmd.Dir = dir
+	return runTool(cmd)
+}
+
+func (tscBackend) TestCommand(ctx context.Context, dir, script string) *exec.Cmd {
+	return nodeTestCommand(ctx, dir, script)
+}
+
+func (tscBackend) EventPipe(cmd *exec.Cmd) (io.ReadCloser, func(), func(), error) {
+	return runnerEventPipe(cmd)
+}
+
+// tsgoBackend is the native TypeScript compiler preview
+// (@typescript/native-preview), and node.
+type tsgoBackend struct{}
+
+// Check looks for tsgo first: if only node is missing, tsgo can still
+// type-check.
+func (tsgoBackend) Check() error {
+	if _, err := exec.LookPath("tsgo"); err != nil {
+		return errors.New("tsgo not found in PATH. Please install with `npm install -g @typescript/native-preview`.")
+	}
+	if !nodeAvailable() {
+		return errNodeMissing
+	}
+	return nil
+}
+
+func (tsgoBackend) Versions() []string {
+	return []string{"node " + toolVersion("node", "--version"), "tsgo " + toolVersion("tsgo", "--version")}
+}
+
+func (tsgoBackend) Compile(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "tsgo", "--project", tsconfigPath, "--pretty", "false")
+	cmd.Dir = dir
+	killTreeOnCancel(cmd)
+	return runTool(cmd)
+}
+
+func (tsgoBackend) TestCommand(ctx context.Context, dir, script string) *exec.Cmd {
+	return nodeTestCommand(ctx, dir, script)
+}
+
+func (tsgoBackend) EventPipe(cmd *exec.Cmd) (io.ReadCloser, func(), func(), error) {
+	return runnerEventPipe(cmd)
+}
+
+// bunBackend runs both tsc and the tests with bun. Bun strips types without
+// checking them, so tsc still does the type-checking. Bun has no permission
+// model, so the vm sandbox is all there is, and Check makes sure it holds.
+type bunBackend struct{}
+
+func (bunBackend) Check() error {
+	if _, err := exec.LookPath("bun"); err != nil {
+		return errors.New("bun not found in PATH. Please install Bun (https://bun.sh/) and try again.")
+	}
+	if _, err := bunTSC(); err != nil {
+		return errors.New("TypeScript not found. Bun type-checks with tsc: please install with `bun add -g typescript`.")
+	}
+	return checkVMSandbox(bunBackend{}, "Bun")
+}
+
+// bunTSC finds the tsc script for bun to run.
+func bunTSC() (string, error) {
+	pkg, err := typeScriptPackageDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(pkg, "bin", "tsc"), nil
+}
+
+func (bunBackend) Versions() []string {
+	versions := []string{"bun " + toolVersion("bun", "--version")}
+	if tsc, err := bunTSC(); err == nil {
+		versions = append(versions, "tsc "+toolVersion("bun", tsc, "--version"))
+	}
+	return versions
+}
+
+func (bunBackend) Compile(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	tsc, err := bunTSC()
+	if err != nil {
+		return "", "", err
+	}
+	cmd := exec.CommandContext(ctx, "bun", tsc, "--project", tsconfigPath)
+	cmd.Dir = dir
+	killTreeOnCancel(cmd)
+	return runTool(cmd)
+}
+
+func (bunBackend) TestCommand(ctx context.Context, dir, script string) *exec.Cmd {
+	return exec.CommandContext(ctx, "bun", "--smol", script)
+}
+
+// Bun's fs doesn't promise to write to inherited file descriptors, so the
+// runner connects back over loopback instead.
+func (bunBackend) EventPipe(cmd *exec.Cmd) (io.ReadCloser, func(), func(), error) {
+	return runnerEventSocket(cmd)
+}
+
+// tsconfigFile is the part of a generated tsconfig.json backends other than
+// tsc need to read back.
+type tsconfigFile struct {
+	Files           []string       `json:"files"`
+	CompilerOptions map[string]any `json:"compilerOptions"`
+}
+
+func readTSConfig(path string) (tsconfigFile, error) {
+	var config tsconfigFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// --- Blanks ---
+//
+// Every starter uses ??? for the parts the learner fills in. The editor
+// highlights them, F3 jumps between them, and a run is refused while any
+// are left, since tsc would only report a confusing parse error.
+
+const blankMarker = "???"
+
+// blank is the position of a ??? in the code: 0-based line and rune column.
+type blank struct {
+	line int
+	col  int
+}
+
+// findBlanks returns every ??? in code, in order.
+func findBlanks(code string) []blank {
+	var blanks []blank
+	for i, line := range strings.Split(code, "\n") {
+		offset := 0
+		for {
+			j := strings.Index(line[offset:], blankMarker)
+			if j < 0 {
+				break
+			}
+			byteCol := offset + j
+			blanks = append(blanks, blank{line: i, col: utf8.RuneCountInString(line[:byteCol])})
+			offset = byteCol + len(blankMarker)
+		}
+	}
+	return blanks
+}
+
+// blankRanges groups blanks by line as column ranges, for highlighting.
+func blankRanges(blanks []blank) map[int][]colRange {
+	ranges := make(map[int][]colRange)
+	width := utf8.RuneCountInString(blankMarker)
+	for _, b := range blanks {
+		ranges[b.line] = append(ranges[b.line], colRange{start: b.col, end: b.col + width})
+	}
+	return ranges
+}
+
+// nextBlank returns the first blank after the given cursor position,
+// wrapping around to the first one.
+func nextBlank(blanks []blank, line, col int) (blank, bool) {
+	if len(blanks) == 0 {
+		return blank{}, false
+	}
+	for _, b := range blanks {
+		if b.line > line || (b.line == line && b.col > col) {
+			return b, true
+		}
+	}
+	return blanks[0], true
+}
+
+// describeBlanks says how many blanks are left and where, e.g.
+// "2 blanks left on lines 3 and 4". Line numbers are 1-based, as in the
+// editor's gutter.
+func describeBlanks(blanks []blank) string {
+	var lines []string
+	for _, b := range blanks {
+		n := fmt.Sprint(b.line + 1)
+		if len(lines) == 0 || lines[len(lines)-1] != n {
+			lines = append(lines, n)
+		}
+	}
+
+	noun := "blank"
+	if len(blanks) != 1 {
+		noun = "blanks"
+	}
+	where := "line " + lines[0]
+	if len(lines) > 1 {
+		where = "lines " + strings.Join(lines[:len(lines)-1], ", ") + " and " + lines[len(lines)-1]
+	}
+	return fmt.Sprintf("%d %s left on %s", len(blanks), noun, where)
+}
+
+// jumpToNextBlank moves the cursor to the next ??? after it.
+func (m *model) jumpToNextBlank() {
+	info := m.textarea.LineInfo()
+	b, ok := nextBlank(findBlanks(m.textarea.Value()), m.textarea.Line(), info.StartColumn+info.ColumnOffset)
+	if !ok {
+		return
+	}
+	m.moveCursor(b.line, b.col)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Result cache ---
+//
+// A run's output depends only on the code, the exercise, the harness and
+// the tools, so runExercise keys it by a hash of all of them and keeps what
+// it sent in ~/.ts-koans/cache. Running the same thing again replays that
+// instead, marked "(cached)". Only runs that would end the same way every
+// time are kept: passes, type errors and tests that failed outright. Entries
+// not replayed for runCacheMaxAge are removed, and so are the oldest ones
+// beyond runCacheMaxEntries.
+
+// runCacheVersion changes whenever what is cached, or how, changes.
+const runCacheVersion = 1
+
+const (
+	runCacheMaxAge     = 30 * 24 * time.Hour
+	runCacheMaxEntries = 1000
+)
+
+// runCacheEnabled is turned off with -no-cache.
+var runCacheEnabled = true
+
+// backendVersions is the session's tool versions, found once.
+var backendVersions = sync.OnceValue(func() []string { return activeBackend.Versions() })
+
+// cachedRun is a cache entry: what a run sent, and whether it passed.
+type cachedRun struct {
+	Msgs   []cachedMsg `json:"msgs"`
+	Passed bool        `json:"passed"`
+}
+
+// cachedMsg is one message, tagged with its type.
+type cachedMsg struct {
+	Type string          `json:"type"`
+	Msg  json.RawMessage `json:"msg"`
+}
+
+// errCachedFailure is returned for a replayed run that failed.
+var errCachedFailure = errors.New("failed (cached)")
+
+// runCacheKey hashes everything a run of userCode depends on.
+func runCacheKey(ctx context.Context, userCode string, files []internal.SourceFile, ex internal.Exercise) string {
+	h := sha256.New()
+	field := func(s string) { fmt.Fprintf(h, "%d:%s\n", len(s), s) }
+	field(fmt.Sprint(runCacheVersion))
+	field(userCode)
+	for _, f := range files {
+		field(f.Name)
+		field(f.Code)
+	}
+	field(fmt.Sprint(ex.Kind, ex.TypeOnly, ex.ESM))
+	field(ex.StarterCode) // expect-error koans are judged by its directives
+	field(ex.TypeAssertions)
+	field(ex.TestScript)
+	opts, _ := json.Marshal(compilerOptionsFor(ex)) // map keys are sorted
+	field(string(opts))
+	field(internal.TypeHarness)
+	field(internal.RunnerMJS)
+	b := backendFor(ctx)
+	field(fmt.Sprintf("%T", b))
+	for _, v := range backendVersions() {
+		field(v)
+	}
+	// The sandbox the tests run in: permission flags, memory limit and so on.
+	for _, arg := range b.TestCommand(ctx, "dir", "script").Args {
+		field(arg)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func runCachePath(key string) string {
+	return filepath.Join(internal.CacheDir(), key+".json")
+}
+
+// replayCachedRun sends the cached results for key, if there are any, and
+// reports whether there were.
+func replayCachedRun(key string, program msgSender) (bool, error) {
+	path := runCachePath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, nil
+	}
+	var run cachedRun
+	if json.Unmarshal(data, &run) != nil {
+		return false, nil
+	}
+	msgs := make([]tea.Msg, 0, len(run.Msgs))
+	for _, m := range run.Msgs {
+		msg, err := decodeCachedMsg(m)
+		if err != nil {
+			program.Send(runnerDebugMsg{Line: fmt.Sprintf("cache %s: %v", key[:12], err)})
+			return false, nil
+		}
+		msgs = append(msgs, msg)
+	}
+	program.Send(runnerDebugMsg{Line: "cache hit " + key[:12]})
+	now := time.Now()
+	os.Chtimes(path, now, now) // keeps it from being pruned
+	for _, msg := range msgs {
+		program.Send(msg)
+	}
+	program.Send(runnerOutputMsg{Line: "♻ (cached) Nothing has changed since an earlier run, so these are its results. Start ts-koans with -no-cache to run it afresh."})
+	if !run.Passed {
+		return true, errCachedFailure
+	}
+	return true, nil
+}
+
+func decodeCachedMsg(m cachedMsg) (tea.Msg, error) {
+	switch m.Type {
+	case "output":
+		return decodeAs[runnerOutputMsg](m.Msg)
+	case "diagnostics":
+		return decodeAs[diagnosticsMsg](m.Msg)
+	case "start":
+		return decodeAs[testStartMsg](m.Msg)
+	case "result":
+		return decodeAs[testResultMsg](m.Msg)
+	case "console":
+		return decodeAs[consoleMsg](m.Msg)
+	case "summary":
+		return decodeAs[testSummaryMsg](m.Msg)
+	}
+	return nil, fmt.Errorf("unknown message type %q", m.Type)
+}
+
+func decodeAs[T tea.Msg](data json.RawMessage) (tea.Msg, error) {
+	var msg T
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+// runRecorder passes messages on, keeping the ones worth replaying.
+type runRecorder struct {
+	program msgSender
+	mu      sync.Mutex
+	msgs    []cachedMsg
+}
+
+func (r *runRecorder) Send(msg tea.Msg) {
+	r.program.Send(msg)
+	var typ string
+	switch msg.(type) {
+	case runnerOutputMsg:
+		typ = "output"
+	case diagnosticsMsg:
+		typ = "diagnostics"
+	case testStartMsg:
+		typ = "start"
+	case testResultMsg:
+		typ = "result"
+	case consoleMsg:
+		typ = "console"
+	case testSummaryMsg:
+		typ = "summary"
+	default:
+		return
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs = append(r.msgs, cachedMsg{Type: typ, Msg: data})
+}
+
+// cacheable reports whether a run that ended with err would end the same
+// way every time. Anything else, such as a timeout, running out of memory
+// or a missing tool, is left out.
+func cacheable(err error) bool {
+	return err == nil || errors.Is(err, errTypeCheckFailed) || errors.Is(err, errTestsFailed)
+}
+
+// save writes the recorded run to the cache, replacing the file in one
+// step so a concurrent reader never sees half of it.
+func (r *runRecorder) save(key string, passed bool) error {
+	r.mu.Lock()
+	data, err := json.Marshal(cachedRun{Msgs: r.msgs, Passed: passed})
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(internal.CacheDir(), key+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), runCachePath(key)); err != nil {
+		return err
+	}
+	pruneRunCacheOnce()
+	return nil
+}
+
+// pruneRunCacheOnce prunes the cache the first time a run is saved in a
+// session.
+var pruneRunCacheOnce = sync.OnceFunc(pruneRunCache)
+
+// pruneRunCache removes entries older than runCacheMaxAge, then the oldest
+// of the rest until there are at most runCacheMaxEntries.
+func pruneRunCache() {
+	dir := internal.CacheDir()
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type entry struct {
+		path    string
+		modTime time.Time
+	}
+	var entries []entry
+	for _, e := range dirEntries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		if time.Since(info.ModTime()) > runCacheMaxAge {
+			os.Remove(path)
+			continue
+		}
+		entries = append(entries, entry{path, info.ModTime()})
+	}
+	if len(entries) <= runCacheMaxEntries {
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.Before(entries[j].modTime) })
+	for _, e := range entries[:len(entries)-runCacheMaxEntries] {
+		os.Remove(e.path)
+	}
+}
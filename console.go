@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Console tab ---
+//
+// The output panel has two tabs: Tests, with the compiler's and the test
+// runner's results, and Console, with whatever the koan's code logged during
+// the last run. [F7] switches between them, and [pgup]/[pgdown] scroll the
+// one that is showing.
+
+type outputTab int
+
+const (
+	testsTab outputTab = iota
+	consoleTab
+)
+
+const maxConsoleLines = 1000 // console lines kept for scrolling back
+
+var (
+	consoleWarnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	consoleErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	consoleDebugStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+)
+
+// appendConsole records a console call, one line per line of its text.
+func (m *model) appendConsole(msg consoleMsg) {
+	style := lipgloss.NewStyle()
+	prefix := ""
+	switch msg.Level {
+	case "warn":
+		style, prefix = consoleWarnStyle, "⚠ "
+	case "error":
+		style, prefix = consoleErrorStyle, "✖ "
+	case "debug":
+		style = consoleDebugStyle
+	}
+	for _, l := range strings.Split(prefix+strings.Join(msg.Args, " "), "\n") {
+		m.consoleLines = append(m.consoleLines, style.Render(l))
+	}
+	if len(m.consoleLines) > maxConsoleLines {
+		m.consoleLines = m.consoleLines[len(m.consoleLines)-maxConsoleLines:]
+	}
+}
+
+// switchOutputTab shows the other tab of the output panel.
+func (m *model) switchOutputTab() {
+	if m.outputTab == testsTab {
+		m.outputTab = consoleTab
+	} else {
+		m.outputTab = testsTab
+	}
+	m.outputScroll = 0
+}
+
+// scrollOutput scrolls the output panel by a page; up is positive.
+func (m *model) scrollOutput(pages int) {
+	page := max(m.outputHeight-2, 1) // less the tab bar, keeping a line of context
+	total := len(m.outputLines)
+	if m.outputTab == consoleTab {
+		total = len(m.consoleLines)
+	}
+	m.outputScroll = min(max(m.outputScroll+pages*page, 0), max(total-(m.outputHeight-1), 0))
+}
+
+// renderOutputTabs is the output panel's first line.
+func (m model) renderOutputTabs() string {
+	console := "Console"
+	if n := len(m.consoleLines); n > 0 {
+		console = fmt.Sprintf("Console (%d)", n)
+	}
+	tabs := []string{"Tests", console}
+	for i, t := range tabs {
+		if outputTab(i) == m.outputTab {
+			tabs[i] = activeTabStyle.Render(t)
+		} else {
+			tabs[i] = inactiveTabStyle.Render(t)
+		}
+	}
+	bar := strings.Join(tabs, " ")
+	if m.outputScroll > 0 {
+		bar += inactiveTabStyle.Render(fmt.Sprintf("↓ %d more", m.outputScroll))
+	}
+	return bar
+}
+
+// consoleView is the Console tab's content.
+func (m model) consoleView() []runnerOutputMsg {
+	if len(m.consoleLines) == 0 {
+		return []runnerOutputMsg{{Line: "Nothing was logged. Use console.log in your code to see values here."}}
+	}
+	lines := make([]runnerOutputMsg, len(m.consoleLines))
+	for i, l := range m.consoleLines {
+		lines[i] = runnerOutputMsg{Line: l}
+	}
+	return lines
+}
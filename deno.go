@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// --- Deno backend ---
+//
+// deno check type-checks the koan with a deno.json made from the generated
+// tsconfig.json, deno bundle emits it as one CommonJS file, and deno run
+// runs the tests with read access to the run's temp directory only. Deno
+// reports type errors its own way; denoDiagnostics turns them back into
+// tsc's format so the rest of ts-koans can read them.
+
+type denoBackend struct{}
+
+// denoIgnoredOptions are compiler options Deno sets itself, or that only
+// make sense for tsc's emit.
+var denoIgnoredOptions = map[string]bool{
+	"target": true, "module": true, "moduleResolution": true,
+	"outDir": true, "pretty": true, "noEmit": true, "moduleDetection": true,
+}
+
+func (denoBackend) Check() error {
+	if _, err := exec.LookPath("deno"); err != nil {
+		return errors.New("deno not found in PATH. Please install Deno (https://deno.com/) and try again.")
+	}
+	// deno bundle was removed in Deno 2.0 and came back in 2.4.
+	if fields := strings.Fields(toolVersion("deno", "--version")); len(fields) >= 2 && fields[0] == "deno" {
+		if v := fields[1]; compareVersions(v, "2.0") >= 0 && compareVersions(v, "2.4") < 0 {
+			return fmt.Errorf("Deno %s has no `deno bundle`, which the deno backend needs. Please upgrade to Deno 2.4 or later (`deno upgrade`).", v)
+		}
+	}
+	return checkVMSandbox(denoBackend{}, "Deno")
+}
+
+func (denoBackend) Versions() []string {
+	return []string{toolVersion("deno", "--version")}
+}
+
+func (denoBackend) Compile(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	tsconfig, err := readTSConfig(tsconfigPath)
+	if err != nil {
+		return "", "", fmt.Errorf("read tsconfig.json: %w", err)
+	}
+	config, err := writeDenoConfig(dir, tsconfig.CompilerOptions)
+	if err != nil {
+		return "", "", err
+	}
+
+	args := append([]string{"check", "--quiet", "--config", config, "--unstable-sloppy-imports"}, tsconfig.Files...)
+	stdout, stderr, err := runDeno(ctx, dir, args...)
+	if err != nil {
+		return denoDiagnostics(stdout+stderr, dir), stderr, err
+	}
+	if noEmit, _ := tsconfig.CompilerOptions["noEmit"].(bool); noEmit {
+		return "", stderr, nil
+	}
+	_, stderr, err = runDeno(ctx, dir, "bundle", "--quiet", "--config", config, "--unstable-sloppy-imports",
+		"--format=cjs", "--output=typecheck.js", "typecheck.ts")
+	return "", stderr, err
+}
+
+// runDeno runs deno from dir without colour, so its output can be parsed.
+func runDeno(ctx context.Context, dir string, args ...string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "deno", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(), "NO_COLOR=1")
+	killTreeOnCancel(cmd)
+	return runTool(cmd)
+}
+
+// writeDenoConfig writes a deno.json with the compiler options Deno
+// understands, and returns its path. Deno is strict by default and tsc
+// isn't, so strict is set either way.
+func writeDenoConfig(dir string, options map[string]any) (string, error) {
+	opts := map[string]any{"strict": false}
+	for k, v := range options {
+		if !denoIgnoredOptions[k] {
+			opts[k] = v
+		}
+	}
+	data, err := json.Marshal(map[string]any{"compilerOptions": opts})
+	if err != nil {
+		return "", fmt.Errorf("encode deno.json: %w", err)
+	}
+	path := filepath.Join(dir, "deno.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("write deno.json: %w", err)
+	}
+	return path, nil
+}
+
+func (denoBackend) TestCommand(ctx context.Context, dir, script string) *exec.Cmd {
+	// As with node's permission model, read access is granted by real path,
+	// e.g. /private/var rather than /var on macOS.
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	if real, err := filepath.EvalSymlinks(script); err == nil {
+		script = real
+	}
+	return exec.CommandContext(ctx, "deno", "run", "--quiet", "--no-prompt", "--no-config",
+		"--allow-read="+dir, "--allow-env="+runnerEventsEnv, "--allow-net=127.0.0.1",
+		fmt.Sprintf("--v8-flags=--max-old-space-size=%d", nodeMemoryLimitMB), script)
+}
+
+// Deno can't be handed an extra file descriptor, so the runner connects back
+// over loopback instead.
+func (denoBackend) EventPipe(cmd *exec.Cmd) (io.ReadCloser, func(), func(), error) {
+	return runnerEventSocket(cmd)
+}
+
+var (
+	// denoDiagnosticPattern is the first line of one of Deno's type errors,
+	// e.g. "TS2322 [ERROR]: Type 'string' is not assignable to type 'number'."
+	denoDiagnosticPattern = regexp.MustCompile(`^(?:error: )?TS(\d+) \[(ERROR|WARNING)\]: (.*)$`)
+	// denoLocationPattern is where the error is, a few lines further down,
+	// e.g. "    at file:///tmp/tskoans-123/typecheck.ts:3:7".
+	denoLocationPattern = regexp.MustCompile(`^\s+at (file://\S+):(\d+):(\d+)$`)
+	// denoCaretPattern underlines the excerpt.
+	denoCaretPattern = regexp.MustCompile(`^\s*[\^~]+\s*$`)
+)
+
+// denoDiagnostics rewrites deno check's output in tsc's --pretty false
+// format, with paths relative to dir. The source excerpts Deno prints are
+// dropped, as are the lines it ends with, such as "Found 2 errors.".
+func denoDiagnostics(output, dir string) string {
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+	var out []string
+	code, category, message := "", "", []string{}
+	inMessage := false // until the excerpt starts, indented lines carry on the message
+	flush := func(location string) {
+		if code == "" {
+			return
+		}
+		out = append(out, fmt.Sprintf("%s%s TS%s: %s", location, category, code, message[0]))
+		out = append(out, message[1:]...)
+		code = ""
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if m := denoDiagnosticPattern.FindStringSubmatch(line); m != nil {
+			flush("")
+			code, category, message = m[1], strings.ToLower(m[2]), []string{m[3]}
+			inMessage = true
+			continue
+		}
+		if code == "" {
+			continue
+		}
+		if m := denoLocationPattern.FindStringSubmatch(line); m != nil {
+			flush(fmt.Sprintf("%s(%s,%s): ", denoFilePath(m[1], dir), m[2], m[3]))
+			continue
+		}
+		if denoCaretPattern.MatchString(line) {
+			// The line before was the excerpt, not more of the message.
+			if inMessage && len(message) > 1 {
+				message = message[:len(message)-1]
+			}
+			inMessage = false
+			continue
+		}
+		if inMessage && strings.HasPrefix(line, "  ") && strings.TrimSpace(line) != "" {
+			message = append(message, strings.TrimRight(line, " "))
+			continue
+		}
+		inMessage = false
+	}
+	flush("")
+	return strings.Join(out, "\n")
+}
+
+// denoFilePath turns a file URL from Deno into a path relative to dir.
+func denoFilePath(fileURL, dir string) string {
+	u, err := url.Parse(fileURL)
+	if err != nil {
+		return fileURL
+	}
+	path := filepath.FromSlash(u.Path)
+	if len(u.Path) > 2 && u.Path[0] == '/' && u.Path[2] == ':' { // file:///C:/...
+		path = filepath.FromSlash(u.Path[1:])
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDenoDiagnostics(t *testing.T) {
+	tests := []struct {
+		name   string
+		dir    string
+		output string
+		want   string
+	}{
+		{
+			name: "one error",
+			dir:  "/tmp/tskoans-123",
+			output: `TS2322 [ERROR]: Type 'string' is not assignable to type 'number'.
+const answer: number = "42";
+      ~~~~~~
+    at file:///tmp/tskoans-123/typecheck.ts:1:7
+
+error: Type checking failed.
+`,
+			want: `typecheck.ts(1,7): error TS2322: Type 'string' is not assignable to type 'number'.`,
+		},
+		{
+			name: "multi-line message and indented excerpt",
+			dir:  "/tmp/tskoans-123",
+			output: `TS2345 [ERROR]: Argument of type '{ name: string; age: string; }' is not assignable to parameter of type 'Person'.
+  Types of property 'age' are incompatible.
+    Type 'string' is not assignable to type 'number'.
+  greet({ name: "Ada", age: "36" });
+        ~~~~~~~~~~~~~~~~~~~~~~~~~~
+    at file:///tmp/tskoans-123/typecheck.ts:7:9
+
+TS2304 [ERROR]: Cannot find name 'nope'.
+nope;
+^
+    at file:///tmp/tskoans-123/lib.ts:2:1
+
+Found 2 errors.
+
+error: Type checking failed.
+`,
+			want: `typecheck.ts(7,9): error TS2345: Argument of type '{ name: string; age: string; }' is not assignable to parameter of type 'Person'.
+  Types of property 'age' are incompatible.
+    Type 'string' is not assignable to type 'number'.
+lib.ts(2,1): error TS2304: Cannot find name 'nope'.`,
+		},
+		{
+			name: "Windows path",
+			dir:  filepath.FromSlash("C:/Users/ada/AppData/Local/Temp/tskoans-123"),
+			output: "error: TS2540 [ERROR]: Cannot assign to 'x' because it is a read-only property.\r\n" +
+				"point.x = 3;\r\n" +
+				"      ^\r\n" +
+				"    at file:///C:/Users/ada/AppData/Local/Temp/tskoans-123/typecheck.ts:4:7\r\n",
+			want: `typecheck.ts(4,7): error TS2540: Cannot assign to 'x' because it is a read-only property.`,
+		},
+		{
+			name:   "no errors",
+			dir:    "/tmp/tskoans-123",
+			output: "Check file:///tmp/tskoans-123/typecheck.ts\n",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := denoDiagnostics(tt.output, tt.dir); got != tt.want {
+				t.Errorf("denoDiagnostics() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
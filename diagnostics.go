@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Compiler diagnostics ---
+//
+// tsc reports errors against typecheck.ts, which is the user's code followed
+// by the type harness and the exercise's TypeAssertions. Diagnostics are
+// parsed from tsc's output and sorted by where they point: user errors are
+// shown with the editor's line numbers and a code frame, a failed assertion
+// with the comment that explains it, and harness errors as such.
+
+var (
+	// tscDiagnosticPattern matches e.g.
+	// "typecheck.ts(10,44): error TS2322: Type ..." and, for errors that
+	// aren't tied to a file, "error TS5023: Unknown compiler option ...".
+	tscDiagnosticPattern = regexp.MustCompile(`^(?:(.+)\((\d+),(\d+)\): )?(error|warning|message) TS(\d+): (.*)$`)
+	// tscContinuationPattern matches the indented lines that elaborate on a
+	// diagnostic, e.g. "  Type 'number' is not assignable to type 'string'."
+	tscContinuationPattern = regexp.MustCompile(`^\s+\S`)
+)
+
+// tsDiagnostic is one diagnostic from tsc. A line of output that isn't a
+// diagnostic is kept with Code 0 and the line as Message.
+type tsDiagnostic struct {
+	File     string // as tsc reports it, e.g. "typecheck.ts"; empty for global errors
+	Line     int    // 1-based
+	Column   int    // 1-based
+	Code     int
+	Category string // "error", "warning" or "message"
+	Message  string // first line, then any elaboration lines
+}
+
+// parseDiagnostics parses tsc's --pretty false output.
+func parseDiagnostics(output string) []tsDiagnostic {
+	var diags []tsDiagnostic
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if m := tscDiagnosticPattern.FindStringSubmatch(line); m != nil {
+			d := tsDiagnostic{File: m[1], Category: m[4], Message: m[6]}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			d.Code, _ = strconv.Atoi(m[5])
+			diags = append(diags, d)
+			continue
+		}
+		if n := len(diags); n > 0 && diags[n-1].Code != 0 && tscContinuationPattern.MatchString(line) {
+			diags[n-1].Message += "\n" + line
+			continue
+		}
+		diags = append(diags, tsDiagnostic{Message: line})
+	}
+	return diags
+}
+
+// String formats d the way tsc does.
+func (d tsDiagnostic) String() string {
+	if d.Code == 0 {
+		return d.Message
+	}
+	s := fmt.Sprintf("%s TS%d: %s", d.Category, d.Code, d.Message)
+	if d.File != "" {
+		s = fmt.Sprintf("%s(%d,%d): %s", d.File, d.Line, d.Column, s)
+	}
+	return s
+}
+
+// diagSource says which part of the project a diagnostic points at.
+type diagSource int
+
+const (
+	diagUser      diagSource = iota // the user's code, in any file
+	diagHarness                     // the type harness
+	diagAssertion                   // one of the exercise's TypeAssertions
+	diagOther                       // global errors and unparsed output
+)
+
+// typecheckLayout records which lines of typecheck.ts hold what.
+type typecheckLayout struct {
+	userLines       int // lines 1..userLines are the user's code
+	harnessStart    int
+	assertionsStart int
+}
+
+// buildTypecheck returns the content of typecheck.ts for userCode and where
+// each part of it starts.
+func buildTypecheck(userCode string, ex internal.Exercise) (string, typecheckLayout) {
+	prefix := userCode + "\n\n"
+	layout := typecheckLayout{
+		userLines:    strings.Count(userCode, "\n") + 1,
+		harnessStart: strings.Count(prefix, "\n") + 1,
+	}
+	prefix += internal.TypeHarness + "\n"
+	layout.assertionsStart = strings.Count(prefix, "\n") + 1
+	return prefix + ex.TypeAssertions + "\n", layout
+}
+
+// source classifies a diagnostic.
+func (l typecheckLayout) source(d tsDiagnostic) diagSource {
+	switch {
+	case d.File == "" || d.Code == 0:
+		return diagOther
+	case d.File != "typecheck.ts":
+		return diagUser
+	case d.Line <= l.userLines:
+		return diagUser
+	case d.Line >= l.assertionsStart:
+		return diagAssertion
+	case d.Line >= l.harnessStart:
+		return diagHarness
+	}
+	// The blank lines between the user's code and the harness.
+	return diagUser
+}
+
+// editorMark is a diagnostic placed in one of the editor's files, for the
+// gutter markers and underlines (see markers.go).
+type editorMark struct {
+	File    string // "typecheck.ts" for the main file, or a SourceFile name
+	Line    int    // 0-based, like the textarea's
+	Col     int    // 0-based rune column
+	Len     int    // runes to underline
+	Message string
+}
+
+// diagnosticsMsg carries the editor marks for a failed compile. It is sent
+// after the output lines for the same diagnostics.
+type diagnosticsMsg struct{ Marks []editorMark }
+
+// diagnosticReport turns diagnostics into output lines for one compile.
+type diagnosticReport struct {
+	layout  typecheckLayout
+	sources map[string][]string // file name -> lines
+	expects []tsExpectation     // @ts-expect-error directives in the user's code
+}
+
+func newDiagnosticReport(typecheck string, layout typecheckLayout, files []internal.SourceFile, expects []tsExpectation) diagnosticReport {
+	sources := map[string][]string{"typecheck.ts": strings.Split(typecheck, "\n")}
+	for _, f := range files {
+		sources[f.Name] = strings.Split(f.Code, "\n")
+	}
+	return diagnosticReport{layout: layout, sources: sources, expects: expects}
+}
+
+// send reports diags. Errors that a @ts-expect-error directive expected are
+// left out, and unused directives or wrong codes are explained instead.
+func (r diagnosticReport) send(diags []tsDiagnostic, p msgSender) {
+	var marks []editorMark
+	for _, d := range diags {
+		switch r.layout.source(d) {
+		case diagUser:
+			if d.File == "typecheck.ts" {
+				if msg, ok := r.sendExpectation(d, diags, p); ok {
+					if msg != "" {
+						marks = append(marks, r.mark(d, msg))
+					}
+					continue
+				}
+			}
+			r.sendUserError(d, p)
+			first, _, _ := strings.Cut(d.Message, "\n")
+			marks = append(marks, r.mark(d, fmt.Sprintf("TS%d: %s", d.Code, first)))
+		case diagAssertion:
+			p.Send(runnerOutputMsg{Line: "✗ " + r.assertionDescription(d.Line), Assertion: true})
+			p.Send(runnerOutputMsg{Line: fmt.Sprintf("  TS%d: %s", d.Code, d.Message)})
+		case diagHarness:
+			p.Send(runnerOutputMsg{Line: fmt.Sprintf("⚙ The koan's type harness failed to compile (TS%d: %s). Does your code declare a name the harness also uses, such as Assert or IsType?", d.Code, d.Message)})
+		default:
+			p.Send(runnerOutputMsg{Line: d.String()})
+		}
+	}
+	if len(marks) > 0 {
+		p.Send(diagnosticsMsg{Marks: marks})
+	}
+}
+
+// mark places d in the editor.
+func (r diagnosticReport) mark(d tsDiagnostic, msg string) editorMark {
+	m := editorMark{File: d.File, Line: d.Line - 1, Col: max(d.Column-1, 0), Len: 1, Message: msg}
+	if lines := r.sources[d.File]; d.Line >= 1 && d.Line <= len(lines) {
+		m.Len = tokenLength(lines[d.Line-1], m.Col)
+	}
+	return m
+}
+
+// sendExpectation explains d if it concerns a @ts-expect-error directive,
+// and reports whether it did, along with the problem to mark in the editor
+// ("" if the error was expected).
+func (r diagnosticReport) sendExpectation(d tsDiagnostic, diags []tsDiagnostic, p msgSender) (string, bool) {
+	if e, ok := expectationAt(r.expects, d.Line); ok && d.Code == tsUnusedDirectiveCode {
+		msg := fmt.Sprintf("Line %d expects %s on the next line, but that line compiles. Write code the compiler rejects.", e.Line, e.describe())
+		p.Send(runnerOutputMsg{Line: "❌ " + msg, Assertion: true})
+		return msg, true
+	}
+	if e, ok := coveringExpectation(r.expects, d.Line); ok {
+		if e.Code != 0 && !expectationMet(diags, e) {
+			msg := fmt.Sprintf("Line %d expects %s, but line %d fails with TS%d: %s", e.Line, e.describe(), d.Line, d.Code, d.Message)
+			p.Send(runnerOutputMsg{Line: "❌ " + msg, Assertion: true})
+			return msg, true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// sendUserError shows an error in the user's code with a code frame.
+func (r diagnosticReport) sendUserError(d tsDiagnostic, p msgSender) {
+	where := fmt.Sprintf("Line %d:%d", d.Line, d.Column)
+	if d.File != "typecheck.ts" {
+		where = fmt.Sprintf("%s %d:%d", d.File, d.Line, d.Column)
+	}
+	first, rest, _ := strings.Cut(d.Message, "\n")
+	p.Send(runnerOutputMsg{Line: fmt.Sprintf("❌ %s %s TS%d: %s", where, d.Category, d.Code, first)})
+	if rest != "" {
+		for _, line := range strings.Split(rest, "\n") {
+			p.Send(runnerOutputMsg{Line: line})
+		}
+	}
+
+	lines := r.sources[d.File]
+	if d.Line < 1 || d.Line > len(lines) {
+		return
+	}
+	code := lines[d.Line-1]
+	gutter := fmt.Sprintf("%4d │ ", d.Line)
+	p.Send(runnerOutputMsg{Line: gutter + code})
+	pad := []rune(code)
+	col := min(max(d.Column-1, 0), len(pad))
+	// Keep tabs in the padding so the caret lines up under them.
+	for i := range pad[:col] {
+		if pad[i] != '\t' {
+			pad[i] = ' '
+		}
+	}
+	caret := strings.Repeat("^", tokenLength(code, col))
+	p.Send(runnerOutputMsg{Line: strings.Repeat(" ", len(gutter)-len("│ ")) + "│ " + string(pad[:col]) + caret})
+}
+
+// tokenLength is the length in runes of the identifier or number starting
+// at col in line, or 1 if there is none, so carets cover a whole name.
+func tokenLength(line string, col int) int {
+	runes := []rune(line)
+	n := 0
+	for col+n < len(runes) && (unicode.IsLetter(runes[col+n]) || unicode.IsDigit(runes[col+n]) || runes[col+n] == '_' || runes[col+n] == '$') {
+		n++
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// assertionDescription describes the assertion on line: the comment above
+// it if there is one, otherwise the assertion itself.
+func (r diagnosticReport) assertionDescription(line int) string {
+	lines := r.sources["typecheck.ts"]
+	if line < 1 || line > len(lines) {
+		return fmt.Sprintf("A type assertion on line %d failed", line)
+	}
+	for i := line - 2; i >= r.layout.assertionsStart-1 && i >= 0; i-- {
+		comment, ok := strings.CutPrefix(strings.TrimSpace(lines[i]), "//")
+		if !ok {
+			break
+		}
+		return strings.TrimSpace(comment)
+	}
+	return strings.TrimSpace(lines[line-1])
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Runner events ---
+//
+// runner.mjs reports results on a channel of its own rather than on stdout,
+// so nothing the koan's code prints can pass for a result. On Unix that is
+// file descriptor 3; see events_unix.go and events_windows.go. Each event is
+// one line of JSON:
+//
+//	{"event":"start","name":"..."}
+//	{"event":"pass","name":"..."}
+//	{"event":"fail","name":"...","message":"..."}
+//	{"event":"console","level":"log","args":["...", "{ a: 1 }"]}
+//	{"event":"summary","passed":4,"failed":1,"error":"..."}
+//
+// The summary comes last, and decides whether the koan is solved, unless
+// the runner fails after sending it. Its error is set if the test script
+// threw outside of a test case, or left a rejected promise unhandled.
+// Console events carry what the koan's code logged, in the order it logged
+// it, with each argument already formatted by util.inspect; see console.go.
+
+// runnerEventsEnv tells runner.mjs where to write its events.
+const runnerEventsEnv = "TSKOANS_EVENTS"
+
+type runnerEvent struct {
+	Event   string   `json:"event"`
+	Name    string   `json:"name"`
+	Message string   `json:"message"`
+	Level   string   `json:"level"`
+	Args    []string `json:"args"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+	Error   string   `json:"error"`
+}
+
+// testStartMsg is sent when a test case starts.
+type testStartMsg struct{ Name string }
+
+// testResultMsg is sent when a test case has passed or failed.
+type testResultMsg struct {
+	Name    string
+	Passed  bool
+	Message string // why it failed
+}
+
+// consoleMsg is one call to console.log and friends in the koan's code.
+type consoleMsg struct {
+	Level string   // "log", "info", "warn", "error" or "debug"
+	Args  []string // formatted
+}
+
+// testSummaryMsg ends a test run.
+type testSummaryMsg struct {
+	Passed, Failed int
+	Error          string // the test script threw outside of a test case
+	// TimedOut is set by readRunnerEvents when a case, or the script
+	// itself, ran out of time, which might not happen on another run.
+	TimedOut bool `json:"-"`
+}
+
+// OK reports whether every test passed.
+func (s testSummaryMsg) OK() bool {
+	return s.Failed == 0 && s.Error == ""
+}
+
+// runnerEventMsg is implemented by the messages decoded from runner events.
+type runnerEventMsg interface {
+	// outputLines is how the event shows up in the output panel.
+	outputLines() []runnerOutputMsg
+}
+
+func (testStartMsg) outputLines() []runnerOutputMsg { return nil }
+
+func (msg testResultMsg) outputLines() []runnerOutputMsg {
+	if msg.Passed {
+		return []runnerOutputMsg{{Line: "✅ " + msg.Name}}
+	}
+	lines := []runnerOutputMsg{{Line: "❌ " + msg.Name}}
+	if msg.Message != "" && msg.Message != msg.Name {
+		for _, l := range strings.Split(msg.Message, "\n") {
+			lines = append(lines, runnerOutputMsg{Line: "   " + l})
+		}
+	}
+	return lines
+}
+
+// Console output has a tab of its own.
+func (consoleMsg) outputLines() []runnerOutputMsg { return nil }
+
+func (msg testSummaryMsg) outputLines() []runnerOutputMsg {
+	total := msg.Passed + msg.Failed
+	switch {
+	case msg.Error != "":
+		return []runnerOutputMsg{{Line: "❌ Test failed: " + msg.Error}}
+	case msg.Failed > 0:
+		noun := "tests"
+		if total == 1 {
+			noun = "test"
+		}
+		return []runnerOutputMsg{{Line: fmt.Sprintf("❌ %d of %d %s failed.", msg.Failed, total, noun)}}
+	}
+	return []runnerOutputMsg{{Line: "✅ All tests passed!"}}
+}
+
+// decodeRunnerEvent turns one line of the event stream into a message.
+func decodeRunnerEvent(line []byte) (tea.Msg, error) {
+	var ev runnerEvent
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return nil, err
+	}
+	switch ev.Event {
+	case "start":
+		return testStartMsg{Name: ev.Name}, nil
+	case "pass":
+		return testResultMsg{Name: ev.Name, Passed: true}, nil
+	case "fail":
+		return testResultMsg{Name: ev.Name, Message: ev.Message}, nil
+	case "console":
+		return consoleMsg{Level: ev.Level, Args: ev.Args}, nil
+	case "summary":
+		return testSummaryMsg{Passed: ev.Passed, Failed: ev.Failed, Error: ev.Error}, nil
+	}
+	return nil, fmt.Errorf("unknown runner event %q", ev.Event)
+}
+
+// runnerTimedOut reports whether a failure message from runner.mjs means
+// its case or script ran out of time.
+func runnerTimedOut(message string) bool {
+	return strings.HasPrefix(message, "Timed out after") || strings.HasPrefix(message, "Script execution timed out")
+}
+
+// readRunnerEvents sends the events read from r until it is closed, and
+// returns the summary, or nil if the runner never got that far.
+func readRunnerEvents(r io.Reader, program msgSender) *testSummaryMsg {
+	var summary *testSummaryMsg
+	timedOut := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), tsWorkerMaxLine)
+	for scanner.Scan() {
+		msg, err := decodeRunnerEvent(scanner.Bytes())
+		if err != nil {
+			program.Send(runnerDebugMsg{Line: fmt.Sprintf("runner event: %v", err)})
+			continue
+		}
+		switch msg := msg.(type) {
+		case testResultMsg:
+			timedOut = timedOut || !msg.Passed && runnerTimedOut(msg.Message)
+		case testSummaryMsg:
+			summary = &msg
+		}
+		program.Send(msg)
+	}
+	if summary != nil {
+		summary.TimedOut = timedOut || runnerTimedOut(summary.Error)
+	}
+	return summary
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"net"
+	"os/exec"
+)
+
+// runnerEventSocket gives the runner a loopback connection for its events,
+// for runtimes that can't be passed extra file descriptors. exited must be
+// called once the runner has exited, in case it never connected.
+func runnerEventSocket(cmd *exec.Cmd) (events io.ReadCloser, started, exited func(), err error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cmd.Env = append(cmd.Environ(), runnerEventsEnv+"=tcp:"+ln.Addr().String())
+	r, w := io.Pipe()
+	go func() {
+		conn, err := ln.Accept()
+		ln.Close()
+		if err != nil {
+			w.CloseWithError(err)
+			return
+		}
+		defer conn.Close()
+		_, err = io.Copy(w, conn)
+		w.CloseWithError(err)
+	}()
+	return r, func() {}, func() { ln.Close() }, nil
+}
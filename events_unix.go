@@ -0,0 +1,24 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// runnerEventPipe passes the runner a pipe for its events as an extra file
+// descriptor. started must be called once the runner has been started (or
+// failed to start), and the reader reaches EOF when the runner exits.
+func runnerEventPipe(cmd *exec.Cmd) (events io.ReadCloser, started, exited func(), err error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	fd := 2 + len(cmd.ExtraFiles) // ExtraFiles start after stdin, stdout and stderr
+	cmd.Env = append(cmd.Environ(), runnerEventsEnv+"=fd:"+strconv.Itoa(fd))
+	return r, func() { w.Close() }, func() {}, nil
+}
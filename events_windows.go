@@ -0,0 +1,14 @@
+//go:build windows
+
+package main
+
+import (
+	"io"
+	"os/exec"
+)
+
+// runnerEventPipe uses a loopback connection, since Windows can't pass a
+// child extra file descriptors; see runnerEventSocket.
+func runnerEventPipe(cmd *exec.Cmd) (events io.ReadCloser, started, exited func(), err error) {
+	return runnerEventSocket(cmd)
+}
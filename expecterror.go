@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- "Must not compile" koans ---
+//
+// In an internal.KindExpectError exercise the learner writes code the
+// compiler rejects, under `// @ts-expect-error` lines from the starter. tsc
+// itself reports directives with nothing to suppress (TS2578). A directive
+// may also name the error it expects, e.g. `// @ts-expect-error TS2540`; to
+// check those we compile a second time with the directives switched off and
+// look at what was reported on the lines they cover.
+
+const tsUnusedDirectiveCode = 2578
+
+var (
+	expectErrorPattern = regexp.MustCompile(`^\s*//\s*@ts-expect-error\b(.*)$`)
+	tsCodePattern      = regexp.MustCompile(`\bTS(\d+)\b`)
+)
+
+// tsExpectation is a `// @ts-expect-error` directive in the learner's code.
+type tsExpectation struct {
+	Line int // 1-based line of the directive
+	Last int // last line the directive covers (the next line of code)
+	Code int // expected TS error code, or 0 for any error
+}
+
+// parseExpectations finds the @ts-expect-error directives in code. Since the
+// user's code starts on line 1 of typecheck.ts, the line numbers match tsc's.
+func parseExpectations(code string) []tsExpectation {
+	lines := strings.Split(code, "\n")
+	var expects []tsExpectation
+	for i, line := range lines {
+		m := expectErrorPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		exp := tsExpectation{Line: i + 1, Last: i + 2}
+		if c := tsCodePattern.FindStringSubmatch(m[1]); c != nil {
+			exp.Code, _ = strconv.Atoi(c[1])
+		}
+		// The directive applies to the next line of code, skipping blank lines.
+		for j := i + 1; j < len(lines) && strings.TrimSpace(lines[j]) == ""; j++ {
+			exp.Last = j + 2
+		}
+		expects = append(expects, exp)
+	}
+	return expects
+}
+
+func (e tsExpectation) describe() string {
+	if e.Code == 0 {
+		return "an error"
+	}
+	return fmt.Sprintf("error TS%d", e.Code)
+}
+
+// expectationAt returns the directive on the given line, if any.
+func expectationAt(expects []tsExpectation, line int) (tsExpectation, bool) {
+	for _, e := range expects {
+		if e.Line == line {
+			return e, true
+		}
+	}
+	return tsExpectation{}, false
+}
+
+// checkExpectations verifies an expect-error exercise after its code has
+// compiled: none of the starter's directives may be removed, and each one
+// that names an error code must cover an error with that code.
+func checkExpectations(ctx context.Context, tmpDir, userCode string, files []internal.SourceFile, ex internal.Exercise, program msgSender) error {
+	expects := parseExpectations(userCode)
+	if missing := missingExpectation(parseExpectations(ex.StarterCode), expects); missing != "" {
+		program.Send(runnerOutputMsg{Line: "❌ " + missing, Assertion: true})
+		return fmt.Errorf("%w: missing @ts-expect-error directives", errTypeCheckFailed)
+	}
+
+	var coded []tsExpectation
+	for _, e := range expects {
+		if e.Code != 0 {
+			coded = append(coded, e)
+		}
+	}
+	if len(coded) == 0 {
+		return nil
+	}
+
+	// Compile again without the directives, into a separate directory so
+	// the real build output is left alone.
+	dir := filepath.Join(tmpDir, "expect")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	disabled := strings.ReplaceAll(userCode, "@ts-expect-error", "ts-expect-error (checking)")
+	full, layout := buildTypecheck(disabled, ex)
+	if err := os.WriteFile(filepath.Join(dir, "typecheck.ts"), []byte(full), 0644); err != nil {
+		return err
+	}
+	if err := writeSourceFiles(dir, files); err != nil {
+		return err
+	}
+	tsconfigPath, _, err := writeTSConfig(dir, withNoEmit(ex))
+	if err != nil {
+		return err
+	}
+	stdout, _, err := runTSC(ctx, tsconfigPath, dir)
+	if tscStopped(err, program) {
+		return err
+	}
+
+	diags := parseDiagnostics(stdout)
+	newDiagnosticReport(full, layout, files, expects).send(diags, program)
+	for _, e := range coded {
+		if !expectationMet(diags, e) {
+			return fmt.Errorf("%w: @ts-expect-error on line %d did not match", errTypeCheckFailed, e.Line)
+		}
+	}
+	return nil
+}
+
+// missingExpectation says which of the starter's directives the learner's
+// code no longer has, or returns "" if it has them all. Directives that name
+// an error code are matched by code, so swapping one for a directive that
+// accepts any error doesn't count.
+func missingExpectation(starter, expects []tsExpectation) string {
+	codes := map[int]int{}
+	for _, e := range expects {
+		codes[e.Code]++
+	}
+	for _, e := range starter {
+		if e.Code == 0 {
+			continue
+		}
+		if codes[e.Code] == 0 {
+			return fmt.Sprintf("This koan needs a // @ts-expect-error TS%d line, but there isn't one left. Put it back and write code under it that the compiler rejects with that error.", e.Code)
+		}
+		codes[e.Code]--
+	}
+	if want := len(starter); len(expects) < want {
+		return fmt.Sprintf("This koan needs %d // @ts-expect-error lines, but only %d are left. Put them back and write code under them that the compiler rejects.", want, len(expects))
+	}
+	return ""
+}
+
+// expectationMet reports whether there is an error with the expected code
+// on one of the lines the directive covers.
+func expectationMet(diags []tsDiagnostic, e tsExpectation) bool {
+	for _, d := range diags {
+		if d.File == "typecheck.ts" && d.Line > e.Line && d.Line <= e.Last && d.Code == e.Code {
+			return true
+		}
+	}
+	return false
+}
+
+// coveringExpectation returns the directive whose next line of code is
+// line, if any.
+func coveringExpectation(expects []tsExpectation, line int) (tsExpectation, bool) {
+	for _, e := range expects {
+		if line > e.Line && line <= e.Last {
+			return e, true
+		}
+	}
+	return tsExpectation{}, false
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Multi-file koans ---
+//
+// An exercise's Files are compiled next to the main file as one project.
+// The editor keeps a buffer per file and shows one at a time, with a tab
+// bar above the code; F4 moves to the next tab. Read-only files can be
+// browsed but not changed. The main file's tab is called koan.ts, though on
+// disk it is still typecheck.ts, with the type harness appended.
+
+const mainFileTab = "koan.ts"
+
+var (
+	activeTabStyle   = lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1)
+	inactiveTabStyle = lipgloss.NewStyle().Faint(true).Padding(0, 1)
+)
+
+// loadBuffers fills the editor buffers for the selected exercise from the
+// saved state, falling back to the starter code, and shows the main file.
+func (m *model) loadBuffers() {
+	ex := m.exercises[m.selected]
+	mainFile := ex.StarterCode
+	if code, ok := m.persistentState.Solutions[ex.ID]; ok && code != "" {
+		mainFile = code
+	}
+	m.buffers = []string{mainFile}
+	for _, f := range ex.Files {
+		code := f.Code
+		if saved, ok := m.persistentState.FileSolutions[ex.ID][f.Name]; ok && !f.ReadOnly {
+			code = saved
+		}
+		m.buffers = append(m.buffers, code)
+	}
+	m.activeFile = 0
+	m.textarea.SetValue(mainFile)
+}
+
+// syncBuffer copies the textarea into the active file's buffer.
+func (m *model) syncBuffer() {
+	if m.activeFile < len(m.buffers) {
+		m.buffers[m.activeFile] = m.textarea.Value()
+	}
+}
+
+// mainCode returns the main file's code, whichever tab is showing.
+func (m model) mainCode() string {
+	if m.activeFile == 0 {
+		return m.textarea.Value()
+	}
+	return m.buffers[0]
+}
+
+// fileEdits returns the current code of the exercise's editable files,
+// keyed by name.
+func (m model) fileEdits() map[string]string {
+	ex := m.exercises[m.selected]
+	edits := make(map[string]string)
+	for i, f := range ex.Files {
+		if f.ReadOnly {
+			continue
+		}
+		if m.activeFile == i+1 {
+			edits[f.Name] = m.textarea.Value()
+		} else {
+			edits[f.Name] = m.buffers[i+1]
+		}
+	}
+	return edits
+}
+
+// switchFile shows the next (or previous) tab.
+func (m *model) switchFile(delta int) {
+	n := len(m.buffers)
+	if n < 2 {
+		return
+	}
+	m.syncBuffer()
+	m.activeFile = ((m.activeFile+delta)%n + n) % n
+	m.textarea.SetValue(m.buffers[m.activeFile])
+	m.moveCursor(0, 0)
+}
+
+// activeReadOnly reports whether the file in the editor may not be changed.
+func (m model) activeReadOnly() bool {
+	if m.activeFile == 0 {
+		return false
+	}
+	return m.exercises[m.selected].Files[m.activeFile-1].ReadOnly
+}
+
+// hasTabs reports whether the tab bar is shown.
+func (m model) hasTabs() bool {
+	return len(m.exercises[m.selected].Files) > 0
+}
+
+func (m model) renderTabBar() string {
+	tabs := []string{mainFileTab}
+	for _, f := range m.exercises[m.selected].Files {
+		name := f.Name
+		if f.ReadOnly {
+			name += " 🔒"
+		}
+		tabs = append(tabs, name)
+	}
+	for i, t := range tabs {
+		if i == m.activeFile {
+			tabs[i] = activeTabStyle.Render(t)
+		} else {
+			tabs[i] = inactiveTabStyle.Render(t)
+		}
+	}
+	return strings.Join(tabs, " ")
+}
+
+// projectFiles returns ex.Files with the learner's code in place of the
+// starter code for each file named in edits.
+func projectFiles(ex internal.Exercise, edits map[string]string) []internal.SourceFile {
+	files := make([]internal.SourceFile, len(ex.Files))
+	for i, f := range ex.Files {
+		if code, ok := edits[f.Name]; ok && !f.ReadOnly {
+			f.Code = code
+		}
+		files[i] = f
+	}
+	return files
+}
+
+// solutionEdits returns the reference answers for ex's editable files, in
+// the form projectFiles takes.
+func solutionEdits(ex internal.Exercise) map[string]string {
+	edits := make(map[string]string)
+	for _, f := range ex.Files {
+		if !f.ReadOnly {
+			edits[f.Name] = f.SolutionCode()
+		}
+	}
+	return edits
+}
+
+// writeSourceFiles writes the extra files of a project into dir.
+func writeSourceFiles(dir string, files []internal.SourceFile) error {
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(f.Code), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// describeAllBlanks is describeBlanks for the main file and every other
+// file the learner can edit, or "" if nothing is left to fill in.
+func describeAllBlanks(userCode string, files []internal.SourceFile) string {
+	var parts []string
+	if blanks := findBlanks(userCode); len(blanks) > 0 {
+		if len(files) == 0 {
+			return describeBlanks(blanks)
+		}
+		parts = append(parts, describeBlanks(blanks)+" in "+mainFileTab)
+	}
+	for _, f := range files {
+		if f.ReadOnly {
+			continue
+		}
+		if blanks := findBlanks(f.Code); len(blanks) > 0 {
+			parts = append(parts, describeBlanks(blanks)+" in "+f.Name)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
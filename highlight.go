@@ -30,6 +30,9 @@ var (
 		}
 		return chroma.Coalesce(l)
 	}()
+
+	// blankStyle marks the ??? placeholders the learner still has to fill in.
+	blankStyle = lipgloss.NewStyle().Background(lipgloss.Color("58")).Foreground(lipgloss.Color("229")).Bold(true)
 )
 
 // styledSpan is a fragment of text on a single line with a single style.
@@ -97,6 +100,54 @@ func highlightLines(code string) [][]styledSpan {
 	return result
 }
 
+// colRange is a half-open range [start, end) of rune columns on a line.
+type colRange struct {
+	start int
+	end   int
+}
+
+func (r colRange) contains(col int) bool { return col >= r.start && col < r.end }
+
+// overlaySpans restyles the parts of a line that fall inside any of the
+// given ranges, splitting spans at the range boundaries. It's used to draw
+// things on top of the syntax colors, such as the ??? blanks.
+func overlaySpans(spans []styledSpan, ranges []colRange, restyle func(lipgloss.Style) lipgloss.Style) []styledSpan {
+	if len(ranges) == 0 {
+		return spans
+	}
+	inRange := func(col int) bool {
+		for _, r := range ranges {
+			if r.contains(col) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []styledSpan
+	pos := 0
+	for _, sp := range spans {
+		runes := []rune(sp.text)
+		start := 0
+		for start < len(runes) {
+			// Extend the piece while it stays on the same side of a boundary
+			in := inRange(pos + start)
+			end := start + 1
+			for end < len(runes) && inRange(pos+end) == in {
+				end++
+			}
+			style := sp.style
+			if in {
+				style = restyle(style)
+			}
+			out = append(out, styledSpan{text: string(runes[start:end]), style: style})
+			start = end
+		}
+		pos += len(runes)
+	}
+	return out
+}
+
 // renderStyledLine renders one line of highlighted spans into a string.
 // If showCursor is true, it draws a reverse-video block cursor at cursorCol
 // by splitting the span that contains the cursor position into three parts:
@@ -147,6 +198,8 @@ func (m model) renderHighlightedCode(viewHeight int) string {
 	code := m.textarea.Value()
 	styledLines := highlightLines(code)
 	totalLines := len(styledLines)
+	blanks := blankRanges(findBlanks(code))
+	marks := m.activeMarks()
 
 	// Get cursor position from the textarea (it still tracks editing state)
 	curRow := m.textarea.Line()
@@ -169,8 +222,12 @@ func (m model) renderHighlightedCode(viewHeight int) string {
 			if isCursor {
 				nStyle = cursorLineNumStyle
 			}
-			num := nStyle.Render(fmt.Sprintf("%*d ", numWidth, idx+1))
-			content := renderStyledLine(styledLines[idx], curCol, isCursor)
+			num := nStyle.Render(fmt.Sprintf("%*d", numWidth, idx+1)) + gutterMarker(marks[idx])
+			spans := overlaySpans(styledLines[idx], blanks[idx], func(s lipgloss.Style) lipgloss.Style {
+				return s.Inherit(blankStyle)
+			})
+			spans = overlaySpans(spans, markRanges(marks[idx]), squiggle)
+			content := renderStyledLine(spans, curCol, isCursor)
 			lines = append(lines, num+content)
 		} else {
 			// Past end of file — show tilde like vim
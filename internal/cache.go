@@ -0,0 +1,16 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheDir returns ~/.ts-koans/cache, where the results of runs are kept so
+// an unchanged run can be replayed. It is created the first time it is
+// asked for; that is best-effort.
+var CacheDir = sync.OnceValue(func() string {
+	dir := filepath.Join(getConfigDir(), "cache")
+	os.MkdirAll(dir, 0700)
+	return dir
+})
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config holds the settings in ~/.ts-koans/config.json, all optional.
+// Command-line flags override them.
+type Config struct {
+	Backend     string   `json:"backend,omitempty"`     // how koans are compiled and run: tsc, tsgo, deno or bun
+	TypeScripts []string `json:"typescripts,omitempty"` // TypeScript installs, or node_modules dirs holding them, for the version matrix
+}
+
+func getConfigFilePath() string {
+	return filepath.Join(getConfigDir(), "config.json")
+}
+
+// LoadConfig reads config.json. A missing file is an empty config.
+func LoadConfig() (Config, error) {
+	var config Config
+	path := getConfigFilePath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("%s: %w", path, err)
+	}
+	return config, nil
+}
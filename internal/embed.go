@@ -7,3 +7,9 @@ var TypeHarness string
 
 //go:embed templates/runner.mjs
 var RunnerMJS string
+
+//go:embed templates/tsworker.cjs
+var TSWorkerJS string
+
+//go:embed templates/vmprobe.mjs
+var VMProbeMJS string
@@ -1,17 +1,96 @@
 package internal
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ExerciseKind says how an exercise is judged.
+type ExerciseKind int
+
+const (
+	// KindTypeCheck exercises pass when the code compiles and the assertions
+	// and tests hold.
+	KindTypeCheck ExerciseKind = iota
+	// KindExpectError exercises ask for code the compiler rejects, marked
+	// with `// @ts-expect-error` lines (optionally naming a code, e.g.
+	// `// @ts-expect-error TS2322`). Each must be used, with the right code.
+	KindExpectError
+)
+
+// SourceFile is an extra file in a multi-file koan. It is compiled together
+// with the main file and can be imported from it, e.g. "./monks".
+type SourceFile struct {
+	Name     string // path relative to the main file, e.g. "monks.ts" or "globals.d.ts"
+	Code     string
+	Solution string // reference answer for an editable file; empty means Code
+	ReadOnly bool
+}
+
+// SolutionCode returns the file's reference answer.
+func (f SourceFile) SolutionCode() string {
+	if f.Solution != "" {
+		return f.Solution
+	}
+	return f.Code
+}
+
+// Difficulty levels. An exercise with Level 0 is unrated.
+const (
+	LevelBeginner = iota + 1
+	LevelIntermediate
+	LevelAdvanced
+)
+
+var levelNames = []string{"", "beginner", "intermediate", "advanced"}
+
+// LevelName returns e.g. "beginner" for LevelBeginner, or "" if the level is
+// unrated or unknown.
+func LevelName(level int) string {
+	if level < 0 || level >= len(levelNames) {
+		return ""
+	}
+	return levelNames[level]
+}
+
+// ParseLevel accepts a level by number ("2") or name ("intermediate").
+func ParseLevel(s string) (int, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for i, name := range levelNames {
+		if i > 0 && (s == name || s == strconv.Itoa(i)) {
+			return i, true
+		}
+	}
+	return 0, false
+}
 
 type Exercise struct {
 	ID             string
+	Kind           ExerciseKind
+	TypeOnly       bool // judged by TypeAssertions alone: no TestScript, so no Node.js needed
+	ESM            bool // compiled and run as an ES module, with import/export, top-level await and import.meta
 	title          string
+	chapter        string
 	description    string
 	info           string
+	Level          int      // LevelBeginner..LevelAdvanced, or 0 if unrated
+	Tags           []string // free-form topics, e.g. "generics", for filtering with tag:
+	Minutes        int      // rough time estimate
+	Hints          []string // revealed one at a time in the editor
 	StarterCode    string
+	Solution       string // reference answer, checked by `tskoans validate`
 	TestScript     string
 	Label          string
 	FunctionName   string
 	TypeAssertions string
+	// CompilerOptions are merged over the runner's defaults in the generated
+	// tsconfig.json, e.g. {"strict": true}.
+	CompilerOptions map[string]any
+	// Files are compiled alongside StarterCode, for koans about modules and
+	// declaration files. The editor shows each one in its own tab.
+	Files []SourceFile
 }
 
 func (e Exercise) Title() string {
@@ -20,10 +99,14 @@ func (e Exercise) Title() string {
 	}
 	return e.title
 }
+func (e Exercise) Chapter() string     { return e.chapter }
 func (e Exercise) Description() string { return e.description }
 func (e Exercise) Info() string        { return e.info }
 func (e Exercise) FilterValue() string { return e.title }
 
+// Hint returns the i-th hint with `code` and **bold** spans styled.
+func (e Exercise) Hint(i int) string { return renderInlineMarkup(e.Hints[i]) }
+
 // Styles for the info panel
 var (
 	kw   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#5FFF87"))
@@ -36,14 +119,25 @@ func Exercises() []Exercise {
 		{
 			ID:          "primitives-string",
 			title:       "Primitives: string",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "An entity can have the type `string`",
 			info:        `A ` + kw.Render("string") + ` is a sequence of characters, or even a single character. For instance, ` + code.Render("Linji") + ` is a string, as is ` + code.Render("a") + `. Even ` + code.Render("\"\"") + ` is a string, albeit an empty one. Got something that looks like a number, but it's wrapped in quotes, like ` + code.Render("123") + `? That's a string too!`,
+			Hints: []string{
+				"Look at the value being assigned. What kind of value is \"Linji\"?",
+				"Text values have the type `string`.",
+			},
 			StarterCode: `const monk: ??? = "Linji";`,
+			Solution:    `const monk: string = "Linji";`,
 			TestScript: `
-if (typeof monk !== "string") throw new Error("monk should be a string, got typeof monk: " + typeof monk + ", value: " + monk);
+test("monk should be a string", () => {
+  if (typeof monk !== "string") throw new Error("monk should be a string, got typeof monk: " + typeof monk + ", value: " + monk);
+});
 
-if (monk !== "Linji") throw new Error("monk should be 'Linji'");
+check("monk should be 'Linji'", () => monk === "Linji");
 `,
 			TypeAssertions: `
 // monk should be of type string
@@ -52,13 +146,22 @@ type _Check = Assert<IsType<typeof monk, string>>;
 		{
 			ID:          "primitives-number",
 			title:       "Primitives: number",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "An entity can have the type `number`",
 			info:        `A ` + kw.Render("number") + ` in TS (or JS) can represent both integers and floating-point values. For example, ` + code.Render("1") + `, ` + code.Render("-5") + `, and ` + code.Render("3.14") + ` are all numbers. TS also supports special numeric values like ` + code.Render("Infinity") + ` and ` + code.Render("NaN") + ` (Not a Number). However, TS does ` + bold.Render("not") + ` have separate types for integers and floats; they are all just 'number'.`,
+			Hints: []string{
+				"1 is a numeric value. Which primitive type covers all numbers?",
+				"Integers and floats share the single type `number`.",
+			},
 			StarterCode: `const handsClapping: ??? = 1;`,
+			Solution:    `const handsClapping: number = 1;`,
 			TestScript: `
-if (typeof handsClapping !== "number") throw new Error("handsClapping should be a number");
-if (handsClapping !== 1) throw new Error("handsClapping should be 1");
+check("handsClapping should be a number", () => typeof handsClapping === "number");
+check("handsClapping should be 1", () => handsClapping === 1);
 `,
 			TypeAssertions: `
 // handsClapping should be of type number
@@ -68,13 +171,22 @@ type _Check = Assert<IsType<typeof handsClapping, number>>;
 		{
 			ID:          "primitives-boolean",
 			title:       "Primitives: boolean",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "An entity can have the type `boolean`",
 			info:        `A ` + kw.Render("boolean") + ` represents a logical entity that can be either ` + code.Render("true") + ` or ` + code.Render("false") + `. It's commonly used in conditional statements and logical operations.`,
+			Hints: []string{
+				"`Boolean(false)` produces either true or false.",
+				"The type of true and false is `boolean`.",
+			},
 			StarterCode: `const nature: ??? = Boolean(false);`,
+			Solution:    `const nature: boolean = Boolean(false);`,
 			TestScript: `
-if (typeof nature !== "boolean") throw new Error("nature should be a boolean");
-if (nature !== false) throw new Error("nature should be false");
+check("nature should be a boolean", () => typeof nature === "boolean");
+check("nature should be false", () => nature === false);
 `,
 			TypeAssertions: `
 // nature should be of type boolean
@@ -83,13 +195,22 @@ type _Check = Assert<IsType<typeof nature, boolean>>;
 		{
 			ID:          "primitives-bigint",
 			title:       "Primitives: bigint",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "A very large entity can have the type `bigint`",
 			info:        `A ` + kw.Render("bigint") + ` represents an integer with ` + bold.Render("arbitrary precision") + `. It's useful for working with very large numbers that exceed the safe integer limit for the "number" type. Like big integers! You can create a bigint by appending 'n' to the end of an integer literal (like ` + code.Render("100n") + `), or by using the BigInt constructor.`,
+			Hints: []string{
+				"`BigInt(100)` does not produce a regular number.",
+				"Arbitrary-precision integers have the type `bigint`.",
+			},
 			StarterCode: `const tremendous: ??? = BigInt(100);`,
+			Solution:    `const tremendous: bigint = BigInt(100);`,
 			TestScript: `
-if (typeof tremendous !== "bigint") throw new Error("tremendous should be a bigint");
-if (tremendous !== BigInt(100)) throw new Error("tremendous should be BigInt(100)");
+check("tremendous should be a bigint", () => typeof tremendous === "bigint");
+check("tremendous should be BigInt(100)", () => tremendous === BigInt(100));
 `,
 			TypeAssertions: `
 // tremendous should be of type bigint
@@ -98,17 +219,29 @@ type _Check = Assert<IsType<typeof tremendous, bigint>>;
 		{
 			ID:          "primitives-symbol",
 			title:       "Primitives: Symbol",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "A unique entity can be created with the special function `Symbol()`, and its type is `symbol`.",
 			info:        `A ` + kw.Render("symbol") + ` is a unique and immutable primitive value. This means only one of a kind can exist in your program! Also, you cannot loop over the properties of a Symbol, and they are not included in ` + code.Render("JSON.stringify") + ` output. They're often used as unique keys for object properties to avoid name collisions.`,
+			Hints: []string{
+				"Both values come from calling `Symbol(...)`.",
+				"There are two blanks, and both take the type `symbol`.",
+			},
 			StarterCode: `// A Symbol is truly unique
 const theOne: ???= Symbol("Linji");
 const theOnly: ??? = Symbol("Linji");
+theOne !== theOnly`,
+			Solution: `// A Symbol is truly unique
+const theOne: symbol = Symbol("Linji");
+const theOnly: symbol = Symbol("Linji");
 theOne !== theOnly`,
 			TestScript: `
-if (typeof theOne !== "symbol") throw new Error("theOne should be a symbol");
-if (typeof theOnly !== "symbol") throw new Error("theOnly should be a symbol");
-if (theOne === theOnly) throw new Error("Symbols with the same description are still unique");
+check("theOne should be a symbol", () => typeof theOne === "symbol");
+check("theOnly should be a symbol", () => typeof theOnly === "symbol");
+check("Symbols with the same description are still unique", () => theOne !== theOnly);
 `,
 			TypeAssertions: `
 // theOne and theOnly should be of type symbol
@@ -118,21 +251,37 @@ type _Check2 = Assert<IsType<typeof theOnly, symbol>>;
 		{
 			ID:          "unique-symbol",
 			title:       "Primitives: Unique Symbol",
+			chapter:     "Primitives",
+			Level:       LevelIntermediate,
+			Tags:        []string{"primitives", "symbol"},
+			Minutes:     4,
 			Label:       "",
 			description: "An even more unique entity; even its type is unique",
 			info:        `A ` + kw.Render("unique symbol") + ` is a subtype of symbol that represents a single, specific symbol. You can create a unique symbol using the 'unique symbol' type on a const declaration. This means that the type is not just 'symbol', but a specific, unique type that can ` + bold.Render("only") + ` be assigned to itself.`,
+			Hints: []string{
+				"The blank comes right before the word `symbol`, so it is a modifier.",
+				"A `const` holding its own one-of-a-kind symbol can be typed `unique symbol`.",
+			},
 			StarterCode: `const uniqueOne: ??? symbol = Symbol("one");
 const uniqueTwo: ??? symbol = Symbol("two");
 
 // Both are symbols, so this works:
 const s: symbol = uniqueOne;
 
+// But try swapping them — the compiler won't let you:
+// const nope: typeof uniqueOne = uniqueTwo;  // Error!`,
+			Solution: `const uniqueOne: unique symbol = Symbol("one");
+const uniqueTwo: unique symbol = Symbol("two");
+
+// Both are symbols, so this works:
+const s: symbol = uniqueOne;
+
 // But try swapping them — the compiler won't let you:
 // const nope: typeof uniqueOne = uniqueTwo;  // Error!`,
 			TestScript: `
-if (typeof uniqueOne !== "symbol") throw new Error("uniqueOne should be a symbol");
-if (typeof uniqueTwo !== "symbol") throw new Error("uniqueTwo should be a symbol");
-if (typeof s !== "symbol") throw new Error("s should be a symbol");
+check("uniqueOne should be a symbol", () => typeof uniqueOne === "symbol");
+check("uniqueTwo should be a symbol", () => typeof uniqueTwo === "symbol");
+check("s should be a symbol", () => typeof s === "symbol");
 `,
 			TypeAssertions: `
 // Both are assignable to symbol, but their types are narrower
@@ -147,11 +296,22 @@ type _Check5 = Assert<IsNotType<typeof uniqueOne, typeof uniqueTwo>>;
 		{
 			ID:          "primitives-any",
 			title:       "Primitives: any",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives"},
+			Minutes:     2,
 			Label:       "",
 			description: "An entity can have `any` type",
 			info:        `The ` + kw.Render("any") + ` type is a powerful escape hatch that allows you to opt out of type checking for a variable. When a variable is of type ` + code.Render("any") + `, it can hold values of any type, and you can perform any operation on it without TypeScript raising an error. However, using ` + code.Render("any") + ` should be done with caution, as it can lead to runtime errors if not used carefully. It's often better to use more specific types or ` + code.Render("unknown") + ` when you want to allow for flexibility while still maintaining some level of type safety.`,
+			Hints: []string{
+				"The variable holds a string, then a number, then a boolean.",
+				"Only one type lets a variable hold anything at all: `any`.",
+			},
 			StarterCode: `let anything: ??? = "one";
 anything = 2;
+anything = false;`,
+			Solution: `let anything: any = "one";
+anything = 2;
 anything = false;`,
 			TestScript: `
 if (anything !== false) throw new Error("anything should be false after assignments");
@@ -163,10 +323,19 @@ type _Check = Assert<IsType<typeof anything, any>>;
 		{
 			ID:          "primitives-null",
 			title:       "Primitives: null",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives", "null"},
+			Minutes:     2,
 			Label:       "",
 			description: "An entity can have `null` type",
 			info:        `The ` + kw.Render("null") + ` type represents the intentional absence of any object value. It's often used to indicate that a variable should be empty or have no value. This will become more useful when we learn about union types a little later.`,
+			Hints: []string{
+				"The only value this variable holds is `null`.",
+				"`null` is a type as well as a value.",
+			},
 			StarterCode: `let nothing: ??? = null;`,
+			Solution:    `let nothing: null = null;`,
 			TestScript: `
 if (nothing !== null) throw new Error("nothing should be null");
 `,
@@ -177,9 +346,18 @@ type _Check = Assert<IsType<typeof nothing, null>>;
 		{
 			ID:          "primitives-undefined",
 			title:       "Primitives: undefined",
+			chapter:     "Primitives",
+			Level:       LevelBeginner,
+			Tags:        []string{"primitives", "null"},
+			Minutes:     2,
 			description: "An unset entity has the type `undefined`",
 			info:        `When an entity is ` + kw.Render("undefined") + `, it means it has been declared but not assigned a value. This is different from ` + code.Render("null") + `, which represents the intentional absence of any object value. In JS and TS, if you declare a variable without initializing it, it will have the value ` + code.Render("undefined") + ` by default. Additionally, if you try to access a property that doesn't exist on an object, it will also return ` + code.Render("undefined") + `.`,
+			Hints: []string{
+				"The only value this variable holds is `undefined`.",
+				"`undefined` is a type as well as a value.",
+			},
 			StarterCode: `let unset: ??? = undefined;`,
+			Solution:    `let unset: undefined = undefined;`,
 			TestScript: `
 if (unset !== undefined) throw new Error("unset should be undefined");
 `,
@@ -192,14 +370,23 @@ type _Check = Assert<IsType<typeof unset, undefined>>;
 		{
 			ID:          "arrays",
 			title:       "Arrays",
+			chapter:     "Arrays",
+			Level:       LevelBeginner,
+			Tags:        []string{"arrays"},
+			Minutes:     2,
 			Label:       "",
 			description: "An array of entities may be defined as an Array",
 			info:        `An ` + kw.Render("array") + ` is an ordered collection of values. In JS, arrays can hold values of any type, and even several different types at once! In TS, however, we can specify the type of values an array can hold.`,
+			Hints: []string{
+				"The blank is a generic type that takes the element type in angle brackets.",
+				"`Array<string>` is an array whose elements are all strings.",
+			},
 			StarterCode: `let anArray: ???<string> = ["one", "two"]; `,
+			Solution:    `let anArray: Array<string> = ["one", "two"]; `,
 			TestScript: `
-if (!Array.isArray(anArray)) throw new Error("anArray should be an array");
-if (anArray.length !== 2) throw new Error("anArray should have length 2");
-if (anArray[0] !== "one" || anArray[1] !== "two") throw new Error("Array elements should match");
+check("anArray should be an array", () => Array.isArray(anArray));
+check("anArray should have length 2", () => anArray.length === 2);
+check("Array elements should match", () => anArray[0] === "one" && anArray[1] === "two");
 `,
 			TypeAssertions: `
 // anArray should be of type Array<string>
@@ -208,13 +395,22 @@ type _Check = Assert<IsType<typeof anArray, Array<string>>>;
 		{
 			ID:          "readonly-arrays",
 			title:       "ReadonlyArrays",
+			chapter:     "Arrays",
+			Level:       LevelBeginner,
+			Tags:        []string{"arrays", "readonly"},
+			Minutes:     2,
 			Label:       "",
 			description: "A readonly array may never change",
 			info:        `A ` + kw.Render("ReadonlyArray") + ` is an array that cannot be modified after its creation. This means you cannot add, remove, or change elements in the array. It's useful for ensuring that data remains immutable and preventing accidental modifications.`,
+			Hints: []string{
+				"You want an array type that can't be modified.",
+				"The read-only version of `Array<T>` is `ReadonlyArray<T>`.",
+			},
 			StarterCode: `let aReadonlyArray: ???<string> = ["steadfast", "unchanging"];`,
+			Solution:    `let aReadonlyArray: ReadonlyArray<string> = ["steadfast", "unchanging"];`,
 			TestScript: `
-if (aReadonlyArray.length !== 2) throw new Error("aReadonlyArray should remain length 2");
-if (aReadonlyArray[0] !== "steadfast" || aReadonlyArray[1] !== "unchanging") throw new Error("Elements should be unchanged");
+check("aReadonlyArray should remain length 2", () => aReadonlyArray.length === 2);
+check("Elements should be unchanged", () => aReadonlyArray[0] === "steadfast" && aReadonlyArray[1] === "unchanging");
 `,
 			TypeAssertions: `
 // aReadonlyArray should be of type ReadonlyArray<string>
@@ -223,11 +419,22 @@ type _Check = Assert<IsType<typeof aReadonlyArray, ReadonlyArray<string>>>;
 		{
 			ID:          "parameter-type-annotations-string",
 			title:       "Parameter Type Annotations: string",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept a string",
 			info:        `In addition to defining the types of variables, we can define the types that our functions will expect! This way, the TS compiler can make sure we're only passing strings to functions that expect strings, for instance.`,
+			Hints: []string{
+				"`name` is added to the string \"Hello \".",
+				"Annotate the parameter as `string`.",
+			},
 			StarterCode: `function hello(name: ???) {
   return "Hello " + name;
+}`,
+			Solution: `function hello(name: string) {
+  return "Hello " + name;
 }`,
 			TestScript: `
 if (hello("world") !== "Hello world") throw new Error('hello("world") should return "Hello world"');
@@ -239,11 +446,22 @@ type _Check = Assert<IsType<Parameters<typeof hello>[0], string>>;
 		{
 			ID:          "parameter-type-annotations-number",
 			title:       "Parameter Type Annotations: number",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept a number",
 			info:        `Telling this function it will always receive a number means we can perform number-like actions on it without worry!`,
+			Hints: []string{
+				"`bar` is added to the number 100, and the result should be a number.",
+				"Annotate the parameter as `number`.",
+			},
 			StarterCode: `function foo(bar: ???) {
   return 100 + bar;
+}`,
+			Solution: `function foo(bar: number) {
+  return 100 + bar;
 }`,
 			TestScript: `
 if (foo(23) !== 123) throw new Error('foo(23) should return 123');
@@ -255,15 +473,26 @@ type _Check = Assert<IsType<Parameters<typeof foo>[0], number>>;
 		{
 			ID:          "parameter-type-annotations-boolean",
 			title:       "Parameter Type Annotations: boolean",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept a boolean value",
 			info:        `This little toy function is purely pedantic. Technically ` + code.Render("!!value") + ` would work with any value, not just a boolean! It's nifty shorthand to convert a value into a boolean.`,
+			Hints: []string{
+				"The function is called with `true` and `false`.",
+				"Annotate the parameter as `boolean`.",
+			},
 			StarterCode: `function isTrue(value: ???) {
   return !!value ? "It is true" : "It is untrue";
+}`,
+			Solution: `function isTrue(value: boolean) {
+  return !!value ? "It is true" : "It is untrue";
 }`,
 			TestScript: `
-if (isTrue(true) !== "It is true") throw new Error('isTrue(true) should return "It is true"');
-if (isTrue(false) !== "It is untrue") throw new Error('isTrue(false) should return "It is untrue"');
+check('isTrue(true) should return "It is true"', () => isTrue(true) === "It is true");
+check('isTrue(false) should return "It is untrue"', () => isTrue(false) === "It is untrue");
 `,
 			TypeAssertions: `
 // The parameter 'value' should be of type boolean
@@ -272,16 +501,27 @@ type _Check = Assert<IsType<Parameters<typeof isTrue>[0], boolean>>;
 		{
 			ID:          "parameter-type-annotations-any",
 			title:       "Parameter Type Annotations: any",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept `any` value",
 			info:        `This is another one to be careful with. Telling the compiler to expect ` + code.Render("any") + ` value means it can't protect us from ourselves. Also, see that ` + code.Render("typeof") + ` operator? We'll play with that more later too!`,
+			Hints: []string{
+				"The function is called with a string, a number and a boolean.",
+				"The parameter must accept anything, so use `any`.",
+			},
 			StarterCode: `function anything(value: ???) {
   return typeof value;
+}`,
+			Solution: `function anything(value: any) {
+  return typeof value;
 }`,
 			TestScript: `
-if (anything("str") !== "string") throw new Error('anything("str") should return "string"');
-if (anything(123) !== "number") throw new Error('anything(123) should return "number"');
-if (anything(false) !== "boolean") throw new Error('anything(false) should return "boolean"');
+check('anything("str") should return "string"', () => anything("str") === "string");
+check('anything(123) should return "number"', () => anything(123) === "number");
+check('anything(false) should return "boolean"', () => anything(false) === "boolean");
 `,
 			TypeAssertions: `
 // The parameter 'value' should be of type any
@@ -290,15 +530,26 @@ type _Check = Assert<IsType<Parameters<typeof anything>[0], any>>;
 		{
 			ID:          "parameter-type-annotations-array",
 			title:       "Parameter Type Annotations: Array",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept an array of values of many types",
 			info:        `Of course, we can also pass arrays as arguments. An equivalent syntax is ` + code.Render("string[]") + `. You can use whichever you prefer!`,
+			Hints: []string{
+				"The blank is followed by `<string>`, so it's a generic type.",
+				"A list of strings is an `Array<string>`.",
+			},
 			StarterCode: `function theyAreTrue(values: ???<string>) {
   return values.every(value => typeof value === "string")
+}`,
+			Solution: `function theyAreTrue(values: Array<string>) {
+  return values.every(value => typeof value === "string")
 }`,
 			TestScript: `
-if (!theyAreTrue(["a", "b", "c"])) throw new Error('theyAreTrue(["a", "b", "c"]) should return true');
-if (theyAreTrue(["a", 2, "c"])) throw new Error('theyAreTrue(["a", 2, "c"]) should return false');
+check('theyAreTrue(["a", "b", "c"]) should return true', () => theyAreTrue(["a", "b", "c"]));
+check('theyAreTrue(["a", 2, "c"]) should return false', () => !theyAreTrue(["a", 2, "c"]));
 `,
 			TypeAssertions: `
 // The parameter 'values' should be of type Array<string>
@@ -308,11 +559,22 @@ type _Check = Assert<IsType<Parameters<typeof theyAreTrue>[0], Array<string>>>;
 		{
 			ID:          "return-type-annotations-string",
 			title:       "Return Type Annotations: string",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can return a string",
 			info:        `Not only can we type the values going into a function - we can also define what should be returned.`,
+			Hints: []string{
+				"`toUpperCase()` returns the same kind of value it was called on.",
+				"The return type goes after the parameter list: `): string`.",
+			},
 			StarterCode: `function stringReturner(value: string): ??? {
   return value.toUpperCase()
+}`,
+			Solution: `function stringReturner(value: string): string {
+  return value.toUpperCase()
 }`,
 			TestScript: `
 if (stringReturner("hello") !== "HELLO") throw new Error('stringReturner("hello") should return "HELLO"');
@@ -324,11 +586,22 @@ type _Check = Assert<IsType<ReturnType<typeof stringReturner>, string>>;
 		{
 			ID:          "return-type-annotations-number",
 			title:       "Return Type Annotations: number",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can return a number",
 			info:        `TypeScript is all about keeping us safe from ourselves. If we tried to return something other than a number here, the compiler would warn us.`,
+			Hints: []string{
+				"A number multiplied by 2 is still a number.",
+				"The return type is `number`.",
+			},
 			StarterCode: `function numberReturner(value: number): ??? {
   return value * 2;
+}`,
+			Solution: `function numberReturner(value: number): number {
+  return value * 2;
 }`,
 			TestScript: `
 if (numberReturner(21) !== 42) throw new Error('numberReturner(21) should return 42');
@@ -340,15 +613,26 @@ type _Check = Assert<IsType<ReturnType<typeof numberReturner>, number>>;
 		{
 			ID:          "return-type-annotations-boolean",
 			title:       "Return Type Annotations: boolean",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can return a boolean value",
 			info:        `Remember to take ` + bold.Render("breaks") + `! Drink some water, stretch!`,
+			Hints: []string{
+				"`!value` flips a boolean.",
+				"The return type is `boolean`.",
+			},
 			StarterCode: `function boolReturner(value: boolean): ??? {
   return !value;
+}`,
+			Solution: `function boolReturner(value: boolean): boolean {
+  return !value;
 }`,
 			TestScript: `
-if (boolReturner(true) !== false) throw new Error('boolReturner(true) should return false');
-if (boolReturner(false) !== true) throw new Error('boolReturner(false) should return true');
+check('boolReturner(true) should return false', () => boolReturner(true) === false);
+check('boolReturner(false) should return true', () => boolReturner(false) === true);
 `,
 			TypeAssertions: `
 // The return type should be boolean
@@ -357,15 +641,26 @@ type _Check = Assert<IsType<ReturnType<typeof boolReturner>, boolean>>;
 		{
 			ID:          "return-type-annotations-any",
 			title:       "Return Type Annotations: any",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can return any value",
 			info:        `Just because you can, does not mean you should.`,
+			Hints: []string{
+				"The function returns whatever it was given.",
+				"The parameter is `any`, so the return type is `any` too.",
+			},
 			StarterCode: `function anyReturner(value: any): ??? {
   return value;
+}`,
+			Solution: `function anyReturner(value: any): any {
+  return value;
 }`,
 			TestScript: `
-if (anyReturner(42) !== 42) throw new Error("anyReturner(42) should return 42");
-if (anyReturner("foo") !== "foo") throw new Error('anyReturner("foo") should return "foo"');
+check("anyReturner(42) should return 42", () => anyReturner(42) === 42);
+check('anyReturner("foo") should return "foo"', () => anyReturner("foo") === "foo");
 `,
 			TypeAssertions: `
 // The return type should be any
@@ -374,11 +669,22 @@ type _Check = Assert<IsType<ReturnType<typeof anyReturner>, any>>;
 		{
 			ID:          "return-type-annotations-void",
 			title:       "Return Type Annotations: void",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can return to the void",
 			info:        `Sometimes we value functions for thir side effects, and ask for nothing in return.`,
+			Hints: []string{
+				"This function doesn't return a value.",
+				"Functions that return nothing have the return type `void`.",
+			},
 			StarterCode: `function voidReturner(value: any): ??? {
   return;
+}`,
+			Solution: `function voidReturner(value: any): void {
+  return;
 }`,
 			TestScript: `
 const result = voidReturner(123);
@@ -391,17 +697,29 @@ type _Check = Assert<IsType<ReturnType<typeof voidReturner>, void>>;
 		{
 			ID:          "anonymous-functions",
 			title:       "Anonymous Functions",
+			chapter:     "Functions",
+			Level:       LevelBeginner,
+			Tags:        []string{"functions"},
+			Minutes:     2,
 			Label:       "",
 			description: "Though nameless, anonymous functions must still abide by typing rules",
 			info:        `Even though there's no specific type annotation here, the compiler sees what you're doing. Many, though, will say that Explicit is better than Implicit.`,
+			Hints: []string{
+				"The array needs a third element.",
+				"Any string will do, for example `\"Linji\"`.",
+			},
 			StarterCode: `const monks = ["Zhaozhou", "Huineng", ???]
 monks.forEach((monk) => {
   console.log(monk + " practices typescript")
+})`,
+			Solution: `const monks = ["Zhaozhou", "Huineng", "Linji"]
+monks.forEach((monk) => {
+  console.log(monk + " practices typescript")
 })`,
 			TestScript: `
-if (monks.length !== 3) throw new Error("There should be three monks in the array");
-if (!monks.includes("Zhaozhou")) throw new Error("Missing Zhaozhou");
-if (!monks.includes("Huineng")) throw new Error("Missing Huineng");
+check("There should be three monks in the array", () => monks.length === 3);
+check("Missing Zhaozhou", () => monks.includes("Zhaozhou"));
+check("Missing Huineng", () => monks.includes("Huineng"));
 `,
 			TypeAssertions: `
 // monks should be an array of strings
@@ -410,11 +728,22 @@ type _Check = Assert<IsType<typeof monks, string[]>>;
 		{
 			ID:          "object-types",
 			title:       "Object Types",
+			chapter:     "Objects",
+			Level:       LevelBeginner,
+			Tags:        []string{"objects"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept an object of a given shape",
 			info:        `Okay, technically there are a few JS quirks that could come into play here. Like "adding" a number to a string results in a concatenation operation. But let's not stray from the path.`,
+			Hints: []string{
+				"`value.baz` is concatenated with `value.bar`, a string.",
+				"Give `baz` the type `string`.",
+			},
 			StarterCode: `function foo(value: {bar: string, baz: ???}): string {
   return value.bar + value.baz;
+}`,
+			Solution: `function foo(value: {bar: string, baz: string}): string {
+  return value.bar + value.baz;
 }`,
 			TestScript: `
 if (foo({ bar: "one", baz: "two" }) !== "onetwo") throw new Error('foo({ bar: "one", baz: "two" }) should return "onetwo"');
@@ -426,12 +755,24 @@ type _Check = Assert<IsType<Parameters<typeof foo>[0], { bar: string; baz: strin
 		{
 			ID:          "object-types-readonly",
 			title:       "Object Types: readonly",
+			chapter:     "Objects",
+			Level:       LevelBeginner,
+			Tags:        []string{"objects", "readonly"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function can accept an object with immutable properties",
 			info:        `Our friend ` + code.Render("readonly") + ` is back!`,
+			Hints: []string{
+				"The blank is a modifier in front of the property name.",
+				"Mark `baz` as `readonly`.",
+			},
 			StarterCode: `function foo(value: {bar: string, ??? baz: string}): void {
   value.bar = "I can change";
   value.baz !== "I cannot";
+}`,
+			Solution: `function foo(value: {bar: string, readonly baz: string}): void {
+  value.bar = "I can change";
+  value.baz !== "I cannot";
 }`,
 			TestScript: `
 // Should be able to reassign bar, but NOT baz
@@ -452,9 +793,17 @@ type _Check = Assert<IsType<{ bar: string, readonly baz: string }, Parameters<ty
 		{
 			ID:          "optional-properties",
 			title:       "Optional Properties",
+			chapter:     "Objects",
+			Level:       LevelBeginner,
+			Tags:        []string{"objects"},
+			Minutes:     2,
 			Label:       "",
 			description: "A function may accept questionable properties",
 			info:        `If you attempt to access the value of an ` + kw.Render("optional") + ` property, you'll get undefined.`,
+			Hints: []string{
+				"The blank is the property name, and the property may be missing.",
+				"Write the name followed by a question mark: `bar?`.",
+			},
 			StarterCode: `// Let foo accept an optional property called bar
 function foo(value: { ???: string }): boolean {
   // bar might be missing!
@@ -462,12 +811,20 @@ function foo(value: { ???: string }): boolean {
     return typeof value.bar === "string";
   }
   return true; // If bar is missing, that's ok
+}`,
+			Solution: `// Let foo accept an optional property called bar
+function foo(value: { bar?: string }): boolean {
+  // bar might be missing!
+  if ("bar" in value) {
+    return typeof value.bar === "string";
+  }
+  return true; // If bar is missing, that's ok
 }`,
 			TestScript: `
 // Should allow missing bar
-if (foo({}) !== true) throw new Error("foo({}) should return true");
+check("foo({}) should return true", () => foo({}) === true);
 // Should allow bar as string
-if (foo({ bar: "baz" }) !== true) throw new Error('foo({ bar: "baz" }) should return true');
+check('foo({ bar: "baz" }) should return true', () => foo({ bar: "baz" }) === true);
 `,
 			TypeAssertions: `
 // bar should be optional. Optional parameters are declared with a ?
@@ -476,11 +833,22 @@ type _Check = Assert<IsType<{ bar?: string }, Parameters<typeof foo>[0]>>;
 		{
 			ID:          "union-types",
 			title:       "Union Types",
+			chapter:     "Unions",
+			Level:       LevelBeginner,
+			Tags:        []string{"unions"},
+			Minutes:     2,
 			Label:       "",
 			description: "Several types may exist in harmony with `|`",
 			info:        `The ` + kw.Render("union") + ` operator ` + code.Render("|") + ` allows us to say that a value can be one of several types. It's common in TS to reach for union types instead of ` + code.Render("any") + ` or an ` + code.Render("enum") + ` (which we'll discuss later).`,
+			Hints: []string{
+				"`something` holds a string and later a number.",
+				"Join the two types with the union operator `|`.",
+			},
 			StarterCode: `let something: string ??? number;
 something = "Hello";
+something = 100;`,
+			Solution: `let something: string | number;
+something = "Hello";
 something = 100;`,
 			TestScript: `
 if (typeof something !== "number") throw new Error("After assignment, something should be a number");
@@ -494,9 +862,17 @@ type _Check = Assert<IsAssignable<typeof something, string | number>>;
 		{
 			ID:          "union-types-narrowing",
 			title:       "Union Types: Narrowing",
+			chapter:     "Unions",
+			Level:       LevelIntermediate,
+			Tags:        []string{"unions", "narrowing"},
+			Minutes:     4,
 			Label:       "",
 			description: "One may narrow the union. The compiler will deduce the most specific type.",
 			info:        `By checking the type of ` + code.Render("foo") + ` at runtime, we can "narrow" its type and guarantee safety within a given branch.`,
+			Hints: []string{
+				"In the else branch, foo can no longer be a string.",
+				"`typeof foo` is `\"number\"` in that branch.",
+			},
 			StarterCode: `function narrow(foo: number | string): true {
   if (typeof foo === "string") {
     // In this branch, TS knows foo is a string
@@ -504,10 +880,18 @@ type _Check = Assert<IsAssignable<typeof something, string | number>>;
   } else {
    return (typeof foo === ???) as true;
   }
+}`,
+			Solution: `function narrow(foo: number | string): true {
+  if (typeof foo === "string") {
+    // In this branch, TS knows foo is a string
+    return (typeof foo === "string") as true;
+  } else {
+   return (typeof foo === "number") as true;
+  }
 }`,
 			TestScript: `
-if (narrow("hello") !== true) throw new Error('narrow("hello") should return true');
-if (narrow(42) !== true) throw new Error('narrow(42) should return true');
+check('narrow("hello") should return true', () => narrow("hello") === true);
+check('narrow(42) should return true', () => narrow(42) === true);
 `,
 			TypeAssertions: `
 // The parameter should accept number or string
@@ -516,66 +900,120 @@ type _CheckParam = Assert<IsType<Parameters<typeof narrow>[0], string | number>>
 		{
 			ID:          "union-types-nullable",
 			title:       "Union Types: Nullable",
+			chapter:     "Unions",
+			Level:       LevelBeginner,
+			Tags:        []string{"unions", "null"},
+			Minutes:     2,
 			Label:       "",
 			description: "A common use of union types is to represent nullable values",
 			info:        `By including ` + code.Render("null") + ` in the union, we can represent values that might be absent. This is often more precise than using ` + code.Render("any") + ` and allows us to take advantage of TypeScript's type checking.`,
+			Hints: []string{
+				"The function checks whether `name` is `null`.",
+				"Add `null` to the union.",
+			},
 			StarterCode: `function greet(name: string | ???): string {
 			  if (name === null) {
 			    return "Hello, monk!";
 			  } else {
 			    return "Hello, " + name + "!";
 			  }
+}`,
+			Solution: `function greet(name: string | null): string {
+			  if (name === null) {
+			    return "Hello, monk!";
+			  } else {
+			    return "Hello, " + name + "!";
+			  }
 }`,
 			TestScript: `
-if (greet("Alice") !== "Hello, Alice!") throw new Error('greet("Alice") should return "Hello, Alice!"');
-if (greet(null) !== "Hello, monk!") throw new Error('greet(null) should return "Hello, monk!"');
+check('greet("Alice") should return "Hello, Alice!"', () => greet("Alice") === "Hello, Alice!");
+check('greet(null) should return "Hello, monk!"', () => greet(null) === "Hello, monk!");
 `,
 		},
 		{
 			ID:          "nullish-coalescing",
 			title:       "Nullish Coalescing",
+			chapter:     "Unions",
+			Level:       LevelBeginner,
+			Tags:        []string{"unions", "null"},
+			Minutes:     2,
 			Label:       "",
 			description: "The nullish coalescing operator `??` can be used to provide a default value when dealing with nullable types",
 			info:        `Why use ` + code.Render("??") + ` instead of ` + code.Render("||") + `? It's a good question. The ` + code.Render("||") + ` operator will return the right-hand side if the left-hand side is falsy, which includes values like ` + code.Render("0") + `, ` + code.Render("\"\"") + `, and ` + code.Render("false") + `. This can lead to unintended consequences if you want to allow those values. The ` + code.Render("??") + ` operator, on the other hand, only returns the right-hand side if the left-hand side is null or undefined, making it a safer choice for providing default values when dealing with nullable types.`,
+			Hints: []string{
+				"You want \"monk\" only when `name` is null or undefined.",
+				"The nullish coalescing operator is `??`.",
+			},
 			StarterCode: `function greet(name: string | null): string {
 			  const actualName = name ??? "monk";
 			  return "Hello, " + actualName + "!";
 			}`,
+			Solution: `function greet(name: string | null): string {
+			  const actualName = name ?? "monk";
+			  return "Hello, " + actualName + "!";
+			}`,
 			TestScript: `
-if (greet("Alice") !== "Hello, Alice!") throw new Error('greet("Alice") should return "Hello, Alice!"');
-if (greet(null) !== "Hello, monk!") throw new Error('greet(null) should return "Hello, monk!"');
+check('greet("Alice") should return "Hello, Alice!"', () => greet("Alice") === "Hello, Alice!");
+check('greet(null) should return "Hello, monk!"', () => greet(null) === "Hello, monk!");
 `,
 		},
 		{
 			ID:          "optional-chaining",
 			title:       "Optional Chaining",
+			chapter:     "Unions",
+			Level:       LevelBeginner,
+			Tags:        []string{"unions", "null"},
+			Minutes:     2,
 			Label:       "",
 			description: "The optional chaining operator `?.` can be used to safely access properties on nullable types",
 			info:        `If an object is null or undefined, the ` + code.Render("?.") + ` operator will short-circuit and return undefined instead of throwing an error. This is especially useful when dealing with deeply nested objects or optional properties.`,
+			Hints: []string{
+				"`monk.mentor` might be undefined, so the property access must be safe.",
+				"Optional chaining is written `?.`.",
+			},
 			StarterCode: `type Monk = {
 			  name: string;
 			  mentor?: Monk;
 }
 function getMentorName(monk: Monk): string {
   return monk.mentor???name ?? "No mentor";
+}`,
+			Solution: `type Monk = {
+			  name: string;
+			  mentor?: Monk;
+}
+function getMentorName(monk: Monk): string {
+  return monk.mentor?.name ?? "No mentor";
 }`,
 			TestScript: `
 const xingsi = { name: "Xingsi" };
 const huineng = { name: "Huineng", mentor: xingsi };
-if (getMentorName(huineng) !== "Xingsi") throw new Error("Huineng's mentor should be Xingsi");
-if (getMentorName(xingsi) !== "No mentor") throw new Error("Xingsi should have no mentor");
+check("Huineng's mentor should be Xingsi", () => getMentorName(huineng) === "Xingsi");
+check("Xingsi should have no mentor", () => getMentorName(xingsi) === "No mentor");
 `,
 		},
 		{
 			ID:          "as-const",
 			title:       "as const",
+			chapter:     "Unions",
+			Level:       LevelIntermediate,
+			Tags:        []string{"unions", "readonly", "literals"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `as const` assertion can be used to make an object literal's properties readonly and its values literal types",
 			info:        `Remember ` + bold.Render("narrowing") + `? The ` + kw.Render("as const") + ` assertion is a way to tell the compiler to infer the narrowest type for an object literal. It makes all properties readonly and infers literal types for the values.`,
+			Hints: []string{
+				"You want the object and its values to be fixed as literals and read-only.",
+				"Use an `as const` assertion.",
+			},
 			StarterCode: `const monk = {
 			  name: "Linji",
 			  age: 800
 } as ???;`,
+			Solution: `const monk = {
+			  name: "Linji",
+			  age: 800
+} as const;`,
 			TestScript: `
 if (monk.name !== "Linji") throw new Error("monk's name should be 'Linji'");
 if (monk.age !== 800) throw new Error("monk's age should be 800");
@@ -590,19 +1028,45 @@ if (!("name" in monk)) throw new Error("monk should have name property");
 		{
 			ID:          "discriminated-unions",
 			title:       "Discriminated Unions",
+			chapter:     "Unions",
+			Level:       LevelIntermediate,
+			Tags:        []string{"unions", "narrowing"},
+			Minutes:     4,
 			Label:       "",
 			description: "A common pattern is to use a literal property to discriminate between types in a union",
 			info:        `This one isn't a specific operator - it's more of a feature of the TS compiler. By providing a property common to all types in a union, we can let the compiler narrow the type based on that property's value!`,
-			StarterCode: `type Circle {
+			Hints: []string{
+				"A Shape can be either of the two types declared above.",
+				"`type Shape = Circle | Square;`",
+			},
+			StarterCode: `type Circle = {
 			    kind: 'circle';
 				radius: number;
 			}
-type Square {
+type Square = {
 			kind: 'square';
 			length: number;
 			}
 type Shape = ???;
 // Create a circle and a square, using the 'kind' property to discriminate between them
+function getArea(shape: Shape) {
+  switch (shape.kind) {
+    case "circle":
+      return Math.PI * shape.radius ** 2; // TypeScript knows shape is Circle here
+    case "square":
+      return shape.length ** 2; // TypeScript knows shape is Square here
+  }
+}`,
+			Solution: `type Circle = {
+			    kind: 'circle';
+				radius: number;
+			}
+type Square = {
+			kind: 'square';
+			length: number;
+			}
+type Shape = Circle | Square;
+// Create a circle and a square, using the 'kind' property to discriminate between them
 function getArea(shape: Shape) {
   switch (shape.kind) {
     case "circle":
@@ -622,17 +1086,30 @@ getArea(mySquare);
 		{
 			ID:          "type-aliases-object-types",
 			title:       "Type Aliases: Object Types",
+			chapter:     "Type Aliases",
+			Level:       LevelBeginner,
+			Tags:        []string{"type-aliases", "objects"},
+			Minutes:     2,
 			Label:       "",
 			description: "One may define a `type` as an object",
 			info:        `The power of types is that we can define custom types with any shape!`,
+			Hints: []string{
+				"You are giving a name to an object type.",
+				"Type aliases are declared with the `type` keyword.",
+			},
 			StarterCode: `??? MyType = {
   foo: string;
   bar: number;
 }
+const val: MyType = { foo: "hi", bar: 123 };`,
+			Solution: `type MyType = {
+  foo: string;
+  bar: number;
+}
 const val: MyType = { foo: "hi", bar: 123 };`,
 			TestScript: `
-if (val.foo !== "hi") throw new Error("foo property should be 'hi'");
-if (val.bar !== 123) throw new Error("bar property should be 123");
+check("foo property should be 'hi'", () => val.foo === "hi");
+check("bar property should be 123", () => val.bar === 123);
 `,
 			TypeAssertions: `
 // MyType should be the object type with foo: string and bar: number
@@ -641,19 +1118,33 @@ type _Check = Assert<IsType<MyType, { foo: string; bar: number }>>;
 		{
 			ID:          "type-aliases-union-types",
 			title:       "Type Aliases: Union Types",
+			chapter:     "Type Aliases",
+			Level:       LevelBeginner,
+			Tags:        []string{"type-aliases"},
+			Minutes:     2,
 			Label:       "",
 			description: "A type may be the union of other types",
 			info:        `Now we're combining concepts; you can use your type aliases in unions. Suppose you want to allow both people and dogs to access your website. Your login function might accept a union of ` + code.Render("Person") + ` and ` + code.Render("Dog") + ` types!`,
+			Hints: []string{
+				"A value of this type may be a MyType or a number.",
+				"`MyType | number`",
+			},
 			StarterCode: `type MyType = {
   foo: string;
   bar: number;
 }
 type MyTypeOrNumber = ???;
+let myVar: MyTypeOrNumber = 100`,
+			Solution: `type MyType = {
+  foo: string;
+  bar: number;
+}
+type MyTypeOrNumber = MyType | number;
 let myVar: MyTypeOrNumber = 100`,
 			TestScript: `
 myVar = { foo: "baz", bar: 123 };
-if (typeof myVar === "number" && myVar !== 100) throw new Error("myVar as number should be 100");
-if (typeof myVar === "object" && myVar.foo !== "baz") throw new Error("myVar as MyType should have foo='baz'");
+check("myVar as number should be 100", () => typeof myVar !== "number" || myVar === 100);
+check("myVar as MyType should have foo='baz'", () => typeof myVar !== "object" || myVar.foo === "baz");
 `,
 			TypeAssertions: `
 // MyTypeOrNumber should be MyType | number
@@ -662,19 +1153,34 @@ type _Check = Assert<IsType<MyTypeOrNumber, MyType | number>>;
 		{
 			ID:          "type-aliases-extending",
 			title:       "Type Aliases: Extending",
+			chapter:     "Type Aliases",
+			Level:       LevelIntermediate,
+			Tags:        []string{"type-aliases"},
+			Minutes:     4,
 			Label:       "",
 			description: "A type may be extended with `&`",
 			info:        `The intersection operator ` + kw.Render("&") + ` allows us to combine types to create new ones. This is often used to extend an existing type with new properties. For instance, if we have a ` + code.Render("Person") + ` type, we can create a ` + code.Render("Monk") + ` type that includes all the properties of ` + code.Render("Person") + ` and adds some new ones!`,
+			Hints: []string{
+				"A Monk has everything a Person has, plus one more property.",
+				"Combine the two types with the intersection operator `&`.",
+			},
 			StarterCode: `type Person = {
   name: string;
 }
 type Monk = Person ??? {
   isMeditating: boolean;
 }
+const m: Monk = { name: "Linji", isMeditating: true };`,
+			Solution: `type Person = {
+  name: string;
+}
+type Monk = Person & {
+  isMeditating: boolean;
+}
 const m: Monk = { name: "Linji", isMeditating: true };`,
 			TestScript: `
-if (m.name !== "Linji") throw new Error("Monk should have correct name");
-if (!m.isMeditating) throw new Error("Monk should have isMeditating property true");
+check("Monk should have correct name", () => m.name === "Linji");
+check("Monk should have isMeditating property true", () => m.isMeditating);
 `,
 			TypeAssertions: `
 // Monk should be the intersection of Person and { isMeditating: boolean }
@@ -682,10 +1188,19 @@ type _Check = Assert<IsType<Monk, Person & { isMeditating: boolean }>>;
 `},
 		{
 			ID:          "type-aliases-immutability",
+			TypeOnly:    true,
 			title:       "Type Aliases: Immutability",
+			chapter:     "Type Aliases",
+			Level:       LevelBeginner,
+			Tags:        []string{"type-aliases", "readonly"},
+			Minutes:     2,
 			Label:       "",
 			description: "A type may not change after its creation",
 			info:        `Once a type alias is declared, it cannot be redeclared or changed (but it can be extended). If you're in a position where you feel like you need to change a type, you might want to be using ` + bold.Render("interfaces") + ` instead - or maybe you need to re-think your model!`,
+			Hints: []string{
+				"Two type aliases can't share a name.",
+				"Rename the second `Constancy` to anything else.",
+			},
 			StarterCode: `// The below code will not compile.
 type Constancy = {
   foo: boolean
@@ -695,10 +1210,15 @@ type Constancy = {
 type Constancy = {
   bar: boolean
 }`,
-			TestScript: `
-// This koan is about the fact that type aliases cannot be redeclared.
-// No runtime test needed.
-`,
+			Solution: `// The below code will not compile.
+type Constancy = {
+  foo: boolean
+}
+
+// Change the name of the second type to make the code compile.
+type Permanence = {
+  bar: boolean
+}`,
 			TypeAssertions: `
 // Only one type Constancy should exist
 type _Check1 = Assert<IsType<Constancy, { foo: boolean }>>;
@@ -706,17 +1226,30 @@ type _Check1 = Assert<IsType<Constancy, { foo: boolean }>>;
 		{
 			ID:          "interfaces",
 			title:       "Interfaces",
+			chapter:     "Interfaces",
+			Level:       LevelBeginner,
+			Tags:        []string{"interfaces", "objects"},
+			Minutes:     2,
 			Label:       "",
 			description: "An interface is very similar to a type",
 			info:        kw.Render("Interfaces") + ` are extremely similar to type aliases. In fact, for object types, they are almost interchangeable. Interfaces can be altered after declaration, while types cannot. It's conventional to use interfaces for most object types, and to use type aliases for things like unions and intersections, but you may walk your own path!`,
+			Hints: []string{
+				"The blank is a keyword that declares an object shape by name.",
+				"Use the `interface` keyword.",
+			},
 			StarterCode: `??? MyInterface {
   foo: string;
   bar: number;
 }
+const obj: MyInterface = { foo: "hello", bar: 123 };`,
+			Solution: `interface MyInterface {
+  foo: string;
+  bar: number;
+}
 const obj: MyInterface = { foo: "hello", bar: 123 };`,
 			TestScript: `
-if (obj.foo !== "hello") throw new Error("foo should be 'hello'");
-if (obj.bar !== 123) throw new Error("bar should be 123");
+check("foo should be 'hello'", () => obj.foo === "hello");
+check("bar should be 123", () => obj.bar === 123);
 `,
 			TypeAssertions: `
 // MyInterface should match the object type { foo: string; bar: number }
@@ -725,19 +1258,34 @@ type _Check = Assert<IsType<MyInterface, { foo: string; bar: number }>>;
 		{
 			ID:          "interfaces-extending",
 			title:       "Interfaces: Extending",
+			chapter:     "Interfaces",
+			Level:       LevelBeginner,
+			Tags:        []string{"interfaces"},
+			Minutes:     2,
 			Label:       "",
 			description: "An interface can be extended as well, with `extends`",
 			info:        `Just like type aliases, interfaces can also be extended to create new interfaces. This is done using the ` + code.Render("extends") + ` keyword. When an interface extends another, it inherits all of its properties and can also add new ones. This is a common way to create more specific types based on more general ones.`,
+			Hints: []string{
+				"Monk builds on Person.",
+				"Interfaces inherit from other interfaces with `extends`.",
+			},
 			StarterCode: `interface Person {
   name: string;
 }
 interface Monk ??? Person {
   isMeditating: boolean
 }
+const m: Monk = { name: "Huineng", isMeditating: true };`,
+			Solution: `interface Person {
+  name: string;
+}
+interface Monk extends Person {
+  isMeditating: boolean
+}
 const m: Monk = { name: "Huineng", isMeditating: true };`,
 			TestScript: `
-if (m.name !== "Huineng") throw new Error("Monk should have correct name");
-if (!m.isMeditating) throw new Error("Monk should have isMeditating property true");
+check("Monk should have correct name", () => m.name === "Huineng");
+check("Monk should have isMeditating property true", () => m.isMeditating);
 `,
 			TypeAssertions: `
 // Monk should extend Person and add isMeditating: boolean
@@ -746,19 +1294,34 @@ type _Check = Assert<IsType<Monk, {name: string, isMeditating: boolean }>>;
 		{
 			ID:          "interfaces-redefining",
 			title:       "Interfaces: Redefining",
+			chapter:     "Interfaces",
+			Level:       LevelIntermediate,
+			Tags:        []string{"interfaces", "declaration-merging"},
+			Minutes:     4,
 			Label:       "",
 			description: "An interface can be redefined freely, merging the declarations",
 			info:        `This is a powerful and confusing feature of interfaces. If you attempt to redeclare an interface, TS will instead merge together all existing declarations of that interface.`,
+			Hints: []string{
+				"Interfaces with the same name are merged together.",
+				"Declare `interface MyInterface` a second time.",
+			},
 			StarterCode: `interface MyInterface {
   foo: string;
 }
 ??? MyInterface {
   bar: number;
 }
+const obj: MyInterface = { foo: "hi", bar: 5 };`,
+			Solution: `interface MyInterface {
+  foo: string;
+}
+interface MyInterface {
+  bar: number;
+}
 const obj: MyInterface = { foo: "hi", bar: 5 };`,
 			TestScript: `
-if (obj.foo !== "hi") throw new Error("foo should be 'hi'");
-if (obj.bar !== 5) throw new Error("bar should be 5");
+check("foo should be 'hi'", () => obj.foo === "hi");
+check("bar should be 5", () => obj.bar === 5);
 `,
 			TypeAssertions: `
 // MyInterface should include both foo and bar
@@ -767,12 +1330,24 @@ type _Check = Assert<IsType<MyInterface, { foo: string; bar: number }>>;
 		{
 			ID:          "tuples",
 			title:       "Tuples",
+			chapter:     "Tuples",
+			Level:       LevelBeginner,
+			Tags:        []string{"tuples"},
+			Minutes:     2,
 			Label:       "",
 			description: "A tuple is an array that knows its shape and size",
 			info:        `A ` + kw.Render("tuple") + ` is a special type of array, of fixed length and order, where each element is explicitly typed.`,
+			Hints: []string{
+				"The second element is checked with `typeof ... === \"number\"`.",
+				"The tuple is `[string, number]`.",
+			},
 			StarterCode: `function foo(myTuple: [string, ???]): true {
   return (typeof myTuple[0] === "string"
   && typeof myTuple[1] === "number") as true;
+}`,
+			Solution: `function foo(myTuple: [string, number]): true {
+  return (typeof myTuple[0] === "string"
+  && typeof myTuple[1] === "number") as true;
 }`,
 			TestScript: `
 if (!foo(["a", 1])) throw new Error('foo(["a", 1]) should return true');
@@ -784,12 +1359,24 @@ type _Check = Assert<IsType<Parameters<typeof foo>[0], [string, number]>>;
 		{
 			ID:          "readonly-tuples",
 			title:       "Readonly Tuples",
+			chapter:     "Tuples",
+			Level:       LevelBeginner,
+			Tags:        []string{"tuples", "readonly"},
+			Minutes:     2,
 			Label:       "",
 			description: "A tuple can be readonly",
 			info:        `A tuple can be ` + kw.Render("readonly") + `. You might need this one day.`,
+			Hints: []string{
+				"The blank is a modifier in front of the tuple type.",
+				"Write `readonly [string, number]`.",
+			},
 			StarterCode: `function foo(myTuple: ??? [string, number]): void {
   console.log(myTuple[0] + " will always be a string")
 }
+const tuple: Readonly<[string, number]> = ["foo", 42] as const;`,
+			Solution: `function foo(myTuple: readonly [string, number]): void {
+  console.log(myTuple[0] + " will always be a string")
+}
 const tuple: Readonly<[string, number]> = ["foo", 42] as const;`,
 			TestScript: `
 // Should accept a readonly tuple
@@ -809,11 +1396,22 @@ type _Check = Assert<IsType<Parameters<typeof foo>[0], Readonly<[string, number]
 		{
 			ID:          "promises",
 			title:       "Promises",
+			chapter:     "Promises",
+			Level:       LevelIntermediate,
+			Tags:        []string{"promises", "async"},
+			Minutes:     4,
 			Label:       "",
 			description: "There exists a special `Promise` type for functions that return promises",
 			info:        `Asynchronous JS is so common that TS has a built-in type for it. By providing a type to the ` + kw.Render("Promise") + ` utility type, we can tell the compiler what the promise will resolve to.`,
+			Hints: []string{
+				"An async function always returns a promise.",
+				"The return type is `Promise<number>`.",
+			},
 			StarterCode: `async function foo(): ???<number> {
   return 100;
+}`,
+			Solution: `async function foo(): Promise<number> {
+  return 100;
 }`,
 			TestScript: `
 foo().then(val => {
@@ -823,18 +1421,65 @@ foo().then(val => {
 			TypeAssertions: `
 // The return type should be Promise<number>
 type _Check = Assert<IsType<ReturnType<typeof foo>, Promise<number>>>;
+`},
+		{
+			ID:          "promises-top-level-await",
+			ESM:         true,
+			title:       "Promises: Top-level await",
+			chapter:     "Promises",
+			Level:       LevelIntermediate,
+			Tags:        []string{"promises", "async", "modules"},
+			Minutes:     4,
+			Label:       "",
+			description: "In an ES module, await works outside of async functions",
+			info:        `An ES module may ` + kw.Render("await") + ` at the top level, and the module finishes loading only once the promise settles. The awaited value has the type the promise resolves to, so no ` + code.Render(".then()") + ` is needed to get at it. This koan runs as an ES module.`,
+			Hints: []string{
+				"`fetchKoan()` returns a `Promise<string>`, but `koan` wants the string itself.",
+				"Use `await fetchKoan()`.",
+			},
+			StarterCode: `async function fetchKoan(): Promise<string> {
+  return "What is the sound of one hand clapping?";
+}
+
+// Get the string the promise resolves to, without .then()
+const koan: string = ??? fetchKoan();`,
+			Solution: `async function fetchKoan(): Promise<string> {
+  return "What is the sound of one hand clapping?";
+}
+
+// Get the string the promise resolves to, without .then()
+const koan: string = await fetchKoan();`,
+			TestScript: `
+check("koan should be the question itself", () => koan === "What is the sound of one hand clapping?");
+`,
+			TypeAssertions: `
+// koan should hold the resolved string, not the promise
+type _Check = Assert<IsType<typeof koan, string>>;
 `},
 		{
 			ID:          "type-assertions-as",
 			title:       "Type Assertions: as",
+			chapter:     "Type Assertions",
+			Level:       LevelIntermediate,
+			Tags:        []string{"type-assertions"},
+			Minutes:     4,
 			Label:       "",
 			description: "Sometimes you may need to tell the compiler what type to expect",
 			info:        `You are a human. There might be a time when you know something your computer doesn't. On these days, you can instruct the compiler to expect a certain type.`,
+			Hints: []string{
+				"You know the result is a number, even though the compiler doesn't.",
+				"Use a type assertion: `... as number`.",
+			},
 			StarterCode: `type SometimesANumber = number | string
 function numberReturner(flag: boolean): SometimesANumber {
   return flag ? "Hello" : 100;
 }
 const myNum: number = numberReturner(false) ??? number;`,
+			Solution: `type SometimesANumber = number | string
+function numberReturner(flag: boolean): SometimesANumber {
+  return flag ? "Hello" : 100;
+}
+const myNum: number = numberReturner(false) as number;`,
 			TestScript: `
 if (myNum !== 100) throw new Error("myNum should be 100");
 `,
@@ -845,10 +1490,19 @@ type _Check = Assert<IsType<typeof myNum, number>>;
 		{
 			ID:          "literal-types",
 			title:       "Literal Types",
+			chapter:     "Literal Types",
+			Level:       LevelBeginner,
+			Tags:        []string{"literal-types", "literals"},
+			Minutes:     2,
 			Label:       "",
 			description: "A type can be literally `anything`",
 			info:        `A literal type is a type that represents a specific value. In this case, the variable ` + code.Render("anything") + ` can only have the value ` + code.Render("anything") + `. This might be useful if you have, say, a union of string literals and you want to ensure a variable is one of those specific strings.`,
+			Hints: []string{
+				"The type only allows one exact value.",
+				"Assign the string `\"anything\"`.",
+			},
 			StarterCode: `let anything: "anything" = ???`,
+			Solution:    `let anything: "anything" = "anything"`,
 			TestScript: `
 if (anything !== "anything") throw new Error('anything should be "anything"');
 `,
@@ -859,10 +1513,20 @@ type _Check = Assert<IsType<typeof anything, "anything">>;
 		{
 			ID:          "literal-types-unions-of-strings",
 			title:       "Literal Types: Unions Of strings",
+			chapter:     "Literal Types",
+			Level:       LevelBeginner,
+			Tags:        []string{"literal-types", "literals"},
+			Minutes:     2,
 			Label:       "",
 			description: "A type can be a union of strings",
 			info:        `Hey, we just talked about this! Maybe you want a variable to only accept one of a few possible values. A union of string literals is a way to do that.`,
+			Hints: []string{
+				"The union is missing the value being assigned below it.",
+				"Add `\"a secret third thing\"` to the union.",
+			},
 			StarterCode: `type ManyThings = "one" | "another" | ???
+let thing: ManyThings = "a secret third thing"`,
+			Solution: `type ManyThings = "one" | "another" | "a secret third thing"
 let thing: ManyThings = "a secret third thing"`,
 			TestScript: `
 thing = "another";
@@ -877,10 +1541,20 @@ type _Check = Assert<IsAssignable<"a secret third thing", ManyThings>>;
 		{
 			ID:          "literal-types-unions-of-numbers",
 			title:       "Literal Types: Unions Of numbers",
+			chapter:     "Literal Types",
+			Level:       LevelBeginner,
+			Tags:        []string{"literal-types", "literals"},
+			Minutes:     2,
 			Label:       "",
 			description: "A type can be a union of numbers",
 			info:        `As with strings, we can create unions of number literals. I think you probably see where this is headed.`,
+			Hints: []string{
+				"The union is missing the value being assigned below it.",
+				"Add `100` to the union.",
+			},
 			StarterCode: `type ManyNumbers = 1 | 2 | ???
+let myNumber: ManyNumbers = 100`,
+			Solution: `type ManyNumbers = 1 | 2 | 100
 let myNumber: ManyNumbers = 100`,
 			TestScript: `
 let n = 1;
@@ -895,14 +1569,27 @@ type _Check = Assert<IsAssignable<100, ManyNumbers>>;
 		},
 		{
 			ID:          "literal-types-as-literal",
+			TypeOnly:    true,
 			title:       "Literal Types: as Literal",
+			chapter:     "Literal Types",
+			Level:       LevelBeginner,
+			Tags:        []string{"literal-types", "literals"},
+			Minutes:     2,
 			Label:       "",
 			description: "Literal types may require assertion",
 			info:        `Sometimes TS won't be able to infer that a variable with a literal union type is actually a specific type. You can be assertive.`,
+			Hints: []string{
+				"A single operator can tell the compiler which type an expression has.",
+				"Use `as`: `myValue as \"bar\"`.",
+			},
 			StarterCode: `function foo(value: "bar" | "baz"): void {}
 const myValue = "bar"
 // Coerce the compiler with a single operator
 foo(myValue ??? "bar")`,
+			Solution: `function foo(value: "bar" | "baz"): void {}
+const myValue = "bar"
+// Coerce the compiler with a single operator
+foo(myValue as "bar")`,
 			TypeAssertions: `
 // Should be assignable to "bar". Use "as bar" to make the compiler pass.
 type _Assert = Assert<IsType<typeof myValue, "bar">>;
@@ -911,15 +1598,29 @@ type _Assert = Assert<IsType<typeof myValue, "bar">>;
 		{
 			ID:          "enums-number",
 			title:       "Enums: number",
+			chapter:     "Enums",
+			Level:       LevelBeginner,
+			Tags:        []string{"enums"},
+			Minutes:     2,
 			Label:       "",
 			description: "Enums are sets of named constants that auto-increment",
 			info:        kw.Render("Enums") + ` are a way to define a set of named constants. By default, they auto-increment from 0, but you can also assign specific values. Here's a funny TS quirk: most TS types don't actually generate any JS code - they're just for the compiler. Enums, on the other hand, do generate real JS objects, which is why they have some unique behaviors.`,
+			Hints: []string{
+				"Numeric enum members count up from the first value.",
+				"Red is 0 and Green is 1, so Blue is `2`.",
+			},
 			StarterCode: `enum Colors {
   Red = 0,
   Green,
   Blue,
 }
 Colors.Blue === ???`,
+			Solution: `enum Colors {
+  Red = 0,
+  Green,
+  Blue,
+}
+Colors.Blue === 2`,
 			TestScript: `
 if (Colors.Blue !== 2) throw new Error("Colors.Blue should be 2");
 `,
@@ -930,15 +1631,29 @@ type _Check = Assert<IsType<typeof Colors.Blue, Colors.Blue>>;
 		{
 			ID:          "enums-string",
 			title:       "Enums: string",
+			chapter:     "Enums",
+			Level:       LevelBeginner,
+			Tags:        []string{"enums"},
+			Minutes:     2,
 			Label:       "",
 			description: "Enums can have string values",
 			info:        `Enums can also have ` + code.Render("string") + ` values. Unlike number enums, string enums do not auto-increment. That would be unreasonable.`,
+			Hints: []string{
+				"Each member of a string enum has the string it was given.",
+				"Blue is `\"BLUE\"`.",
+			},
 			StarterCode: `enum Colors {
   Red = "RED",
   Green = "GREEN",
   Blue = "BLUE",
 }
 Colors.Blue === ???`,
+			Solution: `enum Colors {
+  Red = "RED",
+  Green = "GREEN",
+  Blue = "BLUE",
+}
+Colors.Blue === "BLUE"`,
 			TestScript: `
 if (Colors.Blue !== "BLUE") throw new Error('Colors.Blue should be "BLUE"');
 `,
@@ -949,12 +1664,24 @@ type _Check = Assert<IsType<typeof Colors.Blue, Colors.Blue>>;
 		{
 			ID:          "type-guards-typeof",
 			title:       "Type Guards: typeof",
+			chapter:     "Narrowing",
+			Level:       LevelIntermediate,
+			Tags:        []string{"narrowing"},
+			Minutes:     4,
 			Label:       "",
 			description: "`typeof` can be used in expressions or in types",
 			info:        `The ` + kw.Render("typeof") + ` operator is a powerful tool that we've seen throughout these exercises. It can be used in expressions to check the type of a variable at runtime, and it can also be used in type assertions to infer types based on the value of a variable.`,
+			Hints: []string{
+				"bar should have whatever type foo has.",
+				"Use the `typeof` type operator: `typeof foo`.",
+			},
 			StarterCode: `let foo = "foo";
 let bar: ??? foo;
 bar = "bar"
+typeof foo === typeof bar;`,
+			Solution: `let foo = "foo";
+let bar: typeof foo;
+bar = "bar"
 typeof foo === typeof bar;`,
 			TestScript: `
 if (typeof bar !== "string") throw new Error("bar should be a string");
@@ -967,9 +1694,17 @@ type _Check = Assert<IsType<typeof bar, typeof foo>>;
 		{
 			ID:          "narrowing-in",
 			title:       "Narrowing: in",
+			chapter:     "Narrowing",
+			Level:       LevelIntermediate,
+			Tags:        []string{"narrowing"},
+			Minutes:     4,
 			Label:       "",
 			description: "`in` can be used to narrow types",
 			info:        `The ` + kw.Render("in") + ` operator can be used to check if a property exists in an object. This is useful for narrowing types when you have a union of object types.`,
+			Hints: []string{
+				"You want to check whether the property exists on the object.",
+				"Use the `in` operator: `\"name\" in thing`.",
+			},
 			StarterCode: `type PersonType = {
   name: string
 }
@@ -978,10 +1713,19 @@ type ObjectType = {
 }
 function typeDecider(thing: PersonType | ObjectType): string {
   return "name" ??? thing ? thing.name : thing.foo;
+}`,
+			Solution: `type PersonType = {
+  name: string
+}
+type ObjectType = {
+  foo: string
+}
+function typeDecider(thing: PersonType | ObjectType): string {
+  return "name" in thing ? thing.name : thing.foo;
 }`,
 			TestScript: `
-if (typeDecider({ name: "Linji" }) !== "Linji") throw new Error('typeDecider({ name: "Linji" }) should return "Linji"');
-if (typeDecider({ foo: "bar" }) !== "bar") throw new Error('typeDecider({ foo: "bar" }) should return "bar"');
+check('typeDecider({ name: "Linji" }) should return "Linji"', () => typeDecider({ name: "Linji" }) === "Linji");
+check('typeDecider({ foo: "bar" }) should return "bar"', () => typeDecider({ foo: "bar" }) === "bar");
 `,
 			TypeAssertions: `
 // typeDecider should return "Person" or "Object" as a string
@@ -990,18 +1734,30 @@ type _Check = Assert<IsType<ReturnType<typeof typeDecider>, string>>;
 		{
 			ID:          "type-predicates-is",
 			title:       "Type Predicates: is",
+			chapter:     "Narrowing",
+			Level:       LevelIntermediate,
+			Tags:        []string{"narrowing", "functions"},
+			Minutes:     4,
 			Label:       "",
 			description: "A type predicate will tell the compiler about the type of a variable",
 			info:        `Remember that sometimes you will know more than the compiler. You may use the ` + kw.Render("is") + ` operator to create what is called a type predicate. It takes the form ` + code.Render("myParameterName is someType") + ` and tells the compiler that, ` + bold.Render("if") + ` the function returns true, then the parameter is of the specified type.`,
+			Hints: []string{
+				"The return type tells the compiler what `value` is when the function returns true.",
+				"A type predicate is written `value is Monk`.",
+			},
 			StarterCode: `type Monk = "Linji" | "Zhaozhou"
 function isPerson(value: unknown): value ??? Monk {
   return value === "Linji" || value === "Zhaozhou"
+}`,
+			Solution: `type Monk = "Linji" | "Zhaozhou"
+function isPerson(value: unknown): value is Monk {
+  return value === "Linji" || value === "Zhaozhou"
 }`,
 			TestScript: `
-if (!isPerson("Linji")) throw new Error("isPerson('Linji') should be true");
-if (!isPerson("Zhaozhou")) throw new Error("isPerson('Zhaozhou') should be true");
-if (isPerson("Chris")) throw new Error("isPerson('Chris') should be false");
-if (isPerson(100)) throw new Error("isPerson(100) should be false");
+check("isPerson('Linji') should be true", () => isPerson("Linji"));
+check("isPerson('Zhaozhou') should be true", () => isPerson("Zhaozhou"));
+check("isPerson('Chris') should be false", () => !isPerson("Chris"));
+check("isPerson(100) should be false", () => !isPerson(100));
 `,
 			TypeAssertions: `
 // TypeScript should know that "Linji" is a Monk after the check:
@@ -1015,11 +1771,22 @@ function testNarrowing(x: unknown) {
 		{
 			ID:          "narrowing-never",
 			title:       "Narrowing: never",
+			chapter:     "Narrowing",
+			Level:       LevelAdvanced,
+			Tags:        []string{"narrowing"},
+			Minutes:     6,
 			Label:       "",
 			description: "The `never` type represents values that never occur.",
 			info:        `This is uncommon, but not rare. Some paths are forbidden.`,
+			Hints: []string{
+				"This function never returns normally, because it always throws.",
+				"Its return type is `never`.",
+			},
 			StarterCode: `function fail(message: string): ??? {
   throw new Error(message);
+}`,
+			Solution: `function fail(message: string): never {
+  throw new Error(message);
 }`,
 			TestScript: `
 let threw = false;
@@ -1034,19 +1801,34 @@ type _Assert = Assert<IsType<ReturnType<typeof fail>, never>>;
 		{
 			ID:          "index-signatures",
 			title:       "Index Signatures",
+			chapter:     "Index Signatures",
+			Level:       LevelIntermediate,
+			Tags:        []string{"index-signatures", "objects"},
+			Minutes:     4,
 			Label:       "",
 			description: "Index signatures let you type objects with unknown `key`s, but known value types.",
 			info:        `Sometimes you'll want to create object types, but you won't know the key names at compile time. Don't worry! Somebody has thought of this already. Just provide a type for the keys and a type for the values, and TS will understand the rest!`,
+			Hints: []string{
+				"The keys of `ages` are people's names.",
+				"The key type is `string`.",
+			},
 			StarterCode: `interface PersonAgeMap {
     [ages: ???]: number
 }
 
+const ages: PersonAgeMap = {};
+ages["Chris"] = 36;
+ages["Linji"] = 1159;`,
+			Solution: `interface PersonAgeMap {
+    [ages: string]: number
+}
+
 const ages: PersonAgeMap = {};
 ages["Chris"] = 36;
 ages["Linji"] = 1159;`,
 			TestScript: `
-if (ages["Chris"] !== 36) throw new Error("ages['Chris'] should be 36");
-if (ages["Linji"] !== 1159) throw new Error("ages['Linji'] should be 1159");
+check("ages['Chris'] should be 36", () => ages["Chris"] === 36);
+check("ages['Linji'] should be 1159", () => ages["Linji"] === 1159);
 `,
 			TypeAssertions: `
 // The following line should NOT type-check if value is not a number:
@@ -1058,19 +1840,34 @@ type _KeyCheck = Assert<IsNotType<keyof PersonAgeMap, number>>;
 		{
 			ID:          "index-signature-unknown",
 			title:       "Index Signatures & `unknown`",
+			chapter:     "Index Signatures",
+			Level:       LevelIntermediate,
+			Tags:        []string{"index-signatures"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `unknown` type is a safer alternative to any.",
 			info:        `Why not just use ` + code.Render("any") + `? The ` + code.Render("any") + ` type is a way to opt-out of type checking altogether. The ` + code.Render("unknown") + ` type, on the other hand, forces you to perform some kind of type check before you can use the value, making it a safer choice when you don't know the exact type of the values in your object.`,
+			Hints: []string{
+				"The values can be anything, but should still be checked before use.",
+				"Use `unknown` rather than `any`.",
+			},
 			StarterCode: `interface AnyData {
   [key: string]: ???;
 }
 
+const record: AnyData = {};
+record["foo"] = 123;
+record["bar"] = "hello";`,
+			Solution: `interface AnyData {
+  [key: string]: unknown;
+}
+
 const record: AnyData = {};
 record["foo"] = 123;
 record["bar"] = "hello";`,
 			TestScript: `
-if (record["foo"] !== 123) throw new Error("record['foo'] should be 123");
-if (record["bar"] !== "hello") throw new Error("record['bar'] should be 'hello'");
+check("record['foo'] should be 123", () => record["foo"] === 123);
+check("record['bar'] should be 'hello'", () => record["bar"] === "hello");
 `,
 			TypeAssertions: `
 // The following should fail without a type assertion or guard:
@@ -1088,18 +1885,32 @@ function useFoo(map: AnyData) {
 		{
 			ID:          "intersection-types",
 			title:       "Intersection Types",
+			chapter:     "Intersection Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"intersection-types"},
+			Minutes:     4,
 			Label:       "",
 			description: `Intersection types (using &) combine multiple types into one.`,
 			info:        `We've seen this operator before - we used it to extend types. But did you know it has another use? It can be a little confusing if you're thinking about it in terms of set theory - but an intersection type in TS represents a subset of values that satisfy all of the combined types. For example, if we have a type that represents objects with a name property, and another type that represents objects with an age property, we can create an intersection type that represents objects that have both a name and an age.`,
+			Hints: []string{
+				"A Person has a name and an age.",
+				"Combine the two types with `&`.",
+			},
 			StarterCode: `type HasName = { name: string };
 type HasAge = { age: number };
 
 type Person = HasName ??? HasAge;
 
+const user: Person = { name: "Hakuin", age: 256 };`,
+			Solution: `type HasName = { name: string };
+type HasAge = { age: number };
+
+type Person = HasName & HasAge;
+
 const user: Person = { name: "Hakuin", age: 256 };`,
 			TestScript: `
-if (user.name !== "Hakuin") throw new Error("user.name should be 'Hakuin'");
-if (user.age !== 256) throw new Error("user.age should be 256");
+check("user.name should be 'Hakuin'", () => user.name === "Hakuin");
+check("user.age should be 256", () => user.age === 256);
 `,
 			TypeAssertions: `
 // Should type-check if user is both HasName and HasAge
@@ -1110,18 +1921,32 @@ type _AssertAge = Assert<IsAssignable<typeof user, HasAge>>;
 		{
 			ID:          "generics-type-alias",
 			title:       "Generics: Type Alias",
+			chapter:     "Generics",
+			Level:       LevelBeginner,
+			Tags:        []string{"generics"},
+			Minutes:     2,
 			Label:       "",
 			description: `You can create reusable types with generics.`,
 			info:        `"Why would I need this?" I hear you asking yourself. But it is more common than you might expect. This Box can hold anything. You might want to give it other box-like properties as well. You can do this without creating a separate type for every possible value.`,
+			Hints: []string{
+				"`value` should have whatever type the Box is given.",
+				"Use the type parameter `T`.",
+			},
 			StarterCode: `type Box<T> = {
     value: ???
 }
 
+const numBox: Box<number> = { value: 123 }
+const strBox: Box<string> = { value: "hi" }`,
+			Solution: `type Box<T> = {
+    value: T
+}
+
 const numBox: Box<number> = { value: 123 }
 const strBox: Box<string> = { value: "hi" }`,
 			TestScript: `
-if (numBox.value !== 123) throw new Error("numBox.value should be 123");
-if (strBox.value !== "hi") throw new Error("strBox.value should be 'hi'");
+check("numBox.value should be 123", () => numBox.value === 123);
+check("strBox.value should be 'hi'", () => strBox.value === "hi");
 `,
 			TypeAssertions: `
 // Should type-check for both number and string
@@ -1132,15 +1957,26 @@ type _Str = Assert<IsType<typeof strBox, Box<string>>>;
 		{
 			ID:          "generics-function",
 			title:       "Generics: Function",
+			chapter:     "Generics",
+			Level:       LevelBeginner,
+			Tags:        []string{"generics", "functions"},
+			Minutes:     2,
 			Label:       "",
 			description: `Functions can also be generic!`,
 			info:        `Mayhap you'll need a function that can accept and return any type, so long as they're the same type.`,
+			Hints: []string{
+				"identity returns exactly what it was given.",
+				"The return type is the type parameter `T`.",
+			},
 			StarterCode: `function identity<T>(value: T): ??? {
 	return value;
+}`,
+			Solution: `function identity<T>(value: T): T {
+	return value;
 }`,
 			TestScript: `
-if (identity(123) !== 123) throw new Error("identity(123) should return 123");
-if (identity("hello") !== "hello") throw new Error('identity("hello") should return "hello"');
+check("identity(123) should return 123", () => identity(123) === 123);
+check('identity("hello") should return "hello"', () => identity("hello") === "hello");
 `,
 			TypeAssertions: `
 // The return type should be the same as the parameter type
@@ -1150,16 +1986,27 @@ type _CheckNum = Assert<IsType<ReturnType<typeof identity>, Parameters<typeof id
 		{
 			ID:          "generics-constraints",
 			title:       "Generics: Constraints",
+			chapter:     "Generics",
+			Level:       LevelIntermediate,
+			Tags:        []string{"generics"},
+			Minutes:     4,
 			Label:       "",
 			description: `You can constrain generic types to ensure they have certain properties.`,
 			info:        `The syntax can be overwhelming here. We have a generic function that takes an object of type ` + code.Render("T") + ` and a key of type ` + code.Render("K") + `. The ` + code.Render("K extends keyof T") + ` part is a constraint that says "K must be a key of T". This means that when you call ` + code.Render("getProperty") + `, the compiler will ensure that the key you provide is actually a valid key for the object you're passing in. This allows us to safely access properties on the object without risking a runtime error.`,
+			Hints: []string{
+				"The function returns the property of obj at key.",
+				"Use an indexed access type: `T[K]`.",
+			},
 			StarterCode: `function getProperty<T, K extends keyof T>(obj: T, key: K): ??? {
 	return obj[key];
+}`,
+			Solution: `function getProperty<T, K extends keyof T>(obj: T, key: K): T[K] {
+	return obj[key];
 }`,
 			TestScript: `
 const person = { name: "Dogen", age: 900 };
-if (getProperty(person, "name") !== "Dogen") throw new Error('getProperty(person, "name") should return "Dogen"');
-if (getProperty(person, "age") !== 900) throw new Error('getProperty(person, "age") should return 900');
+check('getProperty(person, "name") should return "Dogen"', () => getProperty(person, "name") === "Dogen");
+check('getProperty(person, "age") should return 900', () => getProperty(person, "age") === 900);
 `,
 			TypeAssertions: `
 // getProperty should return the correct type for a given key
@@ -1172,19 +2019,34 @@ type _Check2 = Assert<IsType<typeof _age, number>>;
 		{
 			ID:          "generics-defaults",
 			title:       "Generics: Defaults",
+			chapter:     "Generics",
+			Level:       LevelAdvanced,
+			Tags:        []string{"generics"},
+			Minutes:     6,
 			Label:       "",
 			description: `Generic type parameters can have defaults, making them optional when using the generic.`,
 			info:        `If no type argument is provided, the default type will be used. That's how defaults work! You knew that. Anyway, here's how you do it in TS. It also works for interfaces, and with multiple type parameters.`,
+			Hints: []string{
+				"`Box` without a type argument is used with a string value.",
+				"Give T the default `string`.",
+			},
 			StarterCode: `type Box<T = ???> = {
 	value: T;
 }
 
+const defaultBox: Box = { value: "hello" };
+// The default can be overridden:
+const numberBox: Box<number> = { value: 123 };`,
+			Solution: `type Box<T = string> = {
+	value: T;
+}
+
 const defaultBox: Box = { value: "hello" };
 // The default can be overridden:
 const numberBox: Box<number> = { value: 123 };`,
 			TestScript: `
-if (defaultBox.value !== "hello") throw new Error("defaultBox.value should be 'hello'");
-if (numberBox.value !== 123) throw new Error("numberBox.value should be 123");
+check("defaultBox.value should be 'hello'", () => defaultBox.value === "hello");
+check("numberBox.value should be 123", () => numberBox.value === 123);
 `,
 			TypeAssertions: `
 // defaultBox should be Box<string>
@@ -1195,10 +2057,19 @@ type _CheckNumber = Assert<IsType<typeof numberBox, Box<number>>>;
 		},
 		{
 			ID:          "keyof",
+			TypeOnly:    true,
 			title:       "The keyof Keyword",
+			chapter:     "Mapped Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"mapped-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: `keyof returns a union of the keys of the given type`,
 			info:        `This comes in handy, believe it or not. You might need to create a type that represents the keys of another type. You can combine this with generics in order to work with the keys of types you might not know at compile time! Doesn't that sound fun?`,
+			Hints: []string{
+				"You want a union of User's property names.",
+				"Use `keyof User`.",
+			},
 			StarterCode: `type User = {
     name: string;
     age: number;
@@ -1210,11 +2081,17 @@ type UserKeys = ???
 const k1: UserKeys = "name"
 const k2: UserKeys = "age"
 const k3: UserKeys = "email"`,
-			TestScript: `
-if (k1 !== "name") throw new Error("k1 should be 'name'");
-if (k2 !== "age") throw new Error("k2 should be 'age'");
-if (k3 !== "email") throw new Error("k3 should be 'email'");
-`,
+			Solution: `type User = {
+    name: string;
+    age: number;
+    email: string;
+}
+
+type UserKeys = keyof User
+
+const k1: UserKeys = "name"
+const k2: UserKeys = "age"
+const k3: UserKeys = "email"`,
 			TypeAssertions: `
 // Should only allow these keys:
 type _Assert = Assert<IsType<UserKeys, "name" | "age" | "email">>;
@@ -1222,10 +2099,19 @@ type _Assert = Assert<IsType<UserKeys, "name" | "age" | "email">>;
 		},
 		{
 			ID:          "mapped-types",
+			TypeOnly:    true,
 			title:       "Mapped Types",
+			chapter:     "Mapped Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"mapped-types", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "A mapped type lets you create a new type by transforming all properties of another type.",
 			info:        `Just as you can ` + code.Render("map") + ` over arrays for create new arrays, you can map over types to create new types.`,
+			Hints: []string{
+				"Every property of BooleanFlags should have the same type.",
+				"Map each key to `boolean`.",
+			},
 			StarterCode: `type User = {
     id: number;
     username: string;
@@ -1242,10 +2128,22 @@ const flags: BooleanFlags = {
     username: false,
     email: true
 }`,
-			TestScript: `
-if (flags.id !== true) throw new Error("flags.id should be true");
-if (flags.username !== false) throw new Error("flags.username should be false");
-`,
+			Solution: `type User = {
+    id: number;
+    username: string;
+    email: string;
+}
+
+// TODO: Make BooleanFlags so that every property of User is a boolean
+type BooleanFlags = {
+    [K in keyof User]: boolean
+}
+
+const flags: BooleanFlags = {
+    id: true,
+    username: false,
+    email: true
+}`,
 			TypeAssertions: `
 // All fields of User should be mapped to a boolean
 type _Check = Assert<IsType<BooleanFlags, { id: boolean; username: boolean; email: boolean }>>;
@@ -1254,9 +2152,17 @@ type _Check = Assert<IsType<BooleanFlags, { id: boolean; username: boolean; emai
 		{
 			ID:          "mapped-type-remove-optional",
 			title:       "Mapped Type: Remove Optional Modifier",
+			chapter:     "Mapped Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"mapped-types", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "Use a mapped type and the `-?` operator to make all properties of `MaybeUser` required.",
 			info:        `There exists syntactic sugar for removing optional modifiers from properties in a mapped type.`,
+			Hints: []string{
+				"The blank follows the key. It's a modifier that changes optional properties.",
+				"`-?` removes the optional modifier.",
+			},
 			StarterCode: `type MaybeUser = {
     id?: number;
     username?: string;
@@ -1267,6 +2173,21 @@ type RequiredUser = {
     [K in keyof MaybeUser]???: MaybeUser[K]
 }
 
+const u: RequiredUser = {
+    id: 1,
+    username: "ada",
+    email: "ada@example.com"
+}`,
+			Solution: `type MaybeUser = {
+    id?: number;
+    username?: string;
+    email?: string;
+}
+
+type RequiredUser = {
+    [K in keyof MaybeUser]-?: MaybeUser[K]
+}
+
 const u: RequiredUser = {
     id: 1,
     username: "ada",
@@ -1283,9 +2204,17 @@ type _Check = Assert<IsType<RequiredUser, { id: number; username: string; email:
 		{
 			ID:          "mapped-type-remove-readonly",
 			title:       "Mapped Type: Remove readonly",
+			chapter:     "Mapped Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"mapped-types", "readonly", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "Use a mapped type and the `-readonly` operator to create a type where all properties are writable.",
 			info:        `Just as you can subtract optional modifiers, you can subtract the ` + code.Render("readonly") + ` modifier from properties in a mapped type. Maybe you need a copy of a user that can be edited.`,
+			Hints: []string{
+				"The blank goes before the key. It's a modifier that changes readonly properties.",
+				"`-readonly` removes the readonly modifier.",
+			},
 			StarterCode: `type ReadonlyUser = {
     readonly id: number;
     readonly username: string;
@@ -1302,6 +2231,23 @@ let user: WritableUser = {
     email: "Ummon@bluecliff.com"
 }
 
+user.id = 100;`,
+			Solution: `type ReadonlyUser = {
+    readonly id: number;
+    readonly username: string;
+    readonly email: string;
+}
+
+type WritableUser = {
+    -readonly [K in keyof ReadonlyUser]: ReadonlyUser[K]
+}
+
+let user: WritableUser = {
+    id: 1,
+    username: "Ummon",
+    email: "Ummon@bluecliff.com"
+}
+
 user.id = 100;`,
 			TestScript: `
 user.id = 2;
@@ -1315,9 +2261,17 @@ type _Check = Assert<IsNotReadonly<WritableUser, "id">>;
 		{
 			ID:          "utility-types-partial",
 			title:       "Utility Types: `Partial`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			info:        `There's no ` + kw.Render("+?") + ` operator to make all properties optional in a mapped type, but there is a built-in utility type that does exactly that.`,
 			description: "The `Partial<T>` utility type makes all properties in T optional.",
+			Hints: []string{
+				"Every property of User should become optional.",
+				"Use `Partial<User>`.",
+			},
 			StarterCode: `type User = {
     id: number;
     username: string;
@@ -1326,14 +2280,25 @@ type _Check = Assert<IsNotReadonly<WritableUser, "id">>;
 
 type MaybeUser = ???
 
+const u: MaybeUser = {};
+u.id = 1;
+u.username = "Bodhidharma";`,
+			Solution: `type User = {
+    id: number;
+    username: string;
+    email: string;
+}
+
+type MaybeUser = Partial<User>
+
 const u: MaybeUser = {};
 u.id = 1;
 u.username = "Bodhidharma";`,
 			TestScript: `
 u.email = "Bodhidharma@east.com";
-if (u.id !== 1) throw new Error("id should be 1");
-if (u.username !== "Bodhidharma") throw new Error("username should be 'Bodhidharma'");
-if (u.email !== "Bodhidharma@east.com") throw new Error("email should be 'Bodhidharma@east.com'");
+check("id should be 1", () => u.id === 1);
+check("username should be 'Bodhidharma'", () => u.username === "Bodhidharma");
+check("email should be 'Bodhidharma@east.com'", () => u.email === "Bodhidharma@east.com");
 `,
 			TypeAssertions: `
 // Should allow all properties to be omitted
@@ -1343,9 +2308,17 @@ type _Check = Assert<IsType<MaybeUser, { id?: number; username?: string; email?:
 		{
 			ID:          "utility-types-required",
 			title:       "Utility Types: `Required`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `Required<T>` utility type makes all properties in T required (not optional).",
 			info:        `This can be thought of as shorthand for using a mapped type to remove optional modifiers from all properties. It's the opposite of ` + code.Render("Partial") + `.`,
+			Hints: []string{
+				"Every property of User should become required.",
+				"Use `Required<User>`.",
+			},
 			StarterCode: `type User = {
     id?: number;
     username?: string;
@@ -1354,15 +2327,28 @@ type _Check = Assert<IsType<MaybeUser, { id?: number; username?: string; email?:
 
 type FullUser = ???
 
+const u: FullUser = {
+    id: 100,
+    username: "Yun-men",
+    email: "yun-men@sumeru.com"
+}`,
+			Solution: `type User = {
+    id?: number;
+    username?: string;
+    email?: string;
+}
+
+type FullUser = Required<User>
+
 const u: FullUser = {
     id: 100,
     username: "Yun-men",
     email: "yun-men@sumeru.com"
 }`,
 			TestScript: `
-if (u.id !== 100) throw new Error("id should be 100");
-if (u.username !== "Yun-men") throw new Error("username should be 'Yun-men'");
-if (u.email !== "yun-men@sumeru.com") throw new Error("email should be 'yun-men@sumeru.com'");
+check("id should be 100", () => u.id === 100);
+check("username should be 'Yun-men'", () => u.username === "Yun-men");
+check("email should be 'yun-men@sumeru.com'", () => u.email === "yun-men@sumeru.com");
 `,
 			TypeAssertions: `
 // Should require all properties
@@ -1372,9 +2358,17 @@ type _Check = Assert<IsType<FullUser, { id: number; username: string; email: str
 		{
 			ID:          "utility-pick",
 			title:       "Utility Types: `Pick`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `Pick<T, K>` utility type creates a new type by selecting a subset of properties from T.",
 			info:        `This is useful when you want to create a type that only includes a few properties from another type.`,
+			Hints: []string{
+				"You want to keep only some of User's properties.",
+				"The utility type is `Pick`.",
+			},
 			StarterCode: `type User = {
     id: number;
     username: string;
@@ -1384,14 +2378,28 @@ type _Check = Assert<IsType<FullUser, { id: number; username: string; email: str
 // TODO: Make UserPreview with only id and username using Pick
 type UserPreview = ???<User, "id" | "username">
 
+const preview: UserPreview = {
+    id: 100,
+    username: "Ikkyu"
+    // email: "should not exist" // should error!
+}`,
+			Solution: `type User = {
+    id: number;
+    username: string;
+    email: string;
+}
+
+// TODO: Make UserPreview with only id and username using Pick
+type UserPreview = Pick<User, "id" | "username">
+
 const preview: UserPreview = {
     id: 100,
     username: "Ikkyu"
     // email: "should not exist" // should error!
 }`,
 			TestScript: `
-if (preview.id !== 100) throw new Error("id should be 100");
-if (preview.username !== "Ikkyu") throw new Error("username should be 'Ikkyu'");
+check("id should be 100", () => preview.id === 100);
+check("username should be 'Ikkyu'", () => preview.username === "Ikkyu");
 `,
 			TypeAssertions: `
 // Should only have id and username, not email
@@ -1402,9 +2410,17 @@ type _Check2 = Assert<IsType<UserPreview, { id: number; username: string }>>;
 		{
 			ID:          "utility-types-omit",
 			title:       "Utility Types: `Omit`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: "Sometimes you must omit, to create something new",
 			info:        `The ` + kw.Render("Omit<T, K>") + ` utility type creates a new type by omitting a subset of properties from T. It's the opposite of ` + code.Render("Pick") + `.`,
+			Hints: []string{
+				"You want to drop one property from User.",
+				"The utility type is `Omit`.",
+			},
 			StarterCode: `type User = {
     id: number;
     username: string;
@@ -1414,14 +2430,28 @@ type _Check2 = Assert<IsType<UserPreview, { id: number; username: string }>>;
 
 type PublicUser = ???<User, "password">
 
+const user: PublicUser = {
+    id: 1,
+    username: "Dongshan",
+    email: "Dongshan@shouchu.com"
+}`,
+			Solution: `type User = {
+    id: number;
+    username: string;
+    email: string;
+    password: string;
+}
+
+type PublicUser = Omit<User, "password">
+
 const user: PublicUser = {
     id: 1,
     username: "Dongshan",
     email: "Dongshan@shouchu.com"
 }`,
 			TestScript: `
-if (user.username !== "Dongshan") throw new Error("username should be 'Dongshan'");
-if (user.email !== "Dongshan@shouchu.com") throw new Error("email should be 'Dongshan@shouchu.com'");
+check("username should be 'Dongshan'", () => user.username === "Dongshan");
+check("email should be 'Dongshan@shouchu.com'", () => user.email === "Dongshan@shouchu.com");
 `,
 			TypeAssertions: `
 // Should not allow password
@@ -1432,9 +2462,17 @@ type _Check2 = Assert<IsType<keyof PublicUser, "id" | "username" | "email">>;
 		{
 			ID:          "utility-types-readonly",
 			title:       "Utility Types: `Readonly`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "readonly", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `Readonly<T>` utility type makes all properties in T readonly.",
 			info:        `This is the equivalent of using a mapped type to add the readonly modifier to all properties. It's a quick way to make an entire type immutable.`,
+			Hints: []string{
+				"Every property of user should become read-only.",
+				"The utility type is `Readonly`.",
+			},
 			StarterCode: `type User = {
 	id: number;
 	username: string;
@@ -1445,10 +2483,21 @@ const user: ???<User> = {
 	id: 1,
 	username: "Dongshan",
 	email: "Dongshan@shouchu.com"
+};`,
+			Solution: `type User = {
+	id: number;
+	username: string;
+	email: string;
+}
+
+const user: Readonly<User> = {
+	id: 1,
+	username: "Dongshan",
+	email: "Dongshan@shouchu.com"
 };`,
 			TestScript: `
-if (user.username !== "Dongshan") throw new Error("username should be 'Dongshan'");
-if (user.email !== "Dongshan@shouchu.com") throw new Error("email should be 'Dongshan@shouchu.com'");
+check("username should be 'Dongshan'", () => user.username === "Dongshan");
+check("email should be 'Dongshan@shouchu.com'", () => user.email === "Dongshan@shouchu.com");
 `,
 			TypeAssertions: `
 // All properties should be readonly
@@ -1458,20 +2507,35 @@ type _Check = Assert<IsType<typeof user, Readonly<User>>>;
 		{
 			ID:          "utility-types-record",
 			title:       "Utility Types: `Record`",
+			chapter:     "Utility Types",
+			Level:       LevelIntermediate,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     4,
 			Label:       "",
 			description: "The `Record<K, T>` utility type constructs an object type whose keys are K and values are T.",
 			info:        `Here's one you'll wind up using a lot: ` + kw.Render("Record") + `. Imagine you're waiting for an API response and know that the keys will be a specific set of strings, but you don't know how many there will be or what the values will look like. You can use Record to type this response!`,
+			Hints: []string{
+				"You want an object type with a fixed set of keys and one value type.",
+				"The utility type is `Record`.",
+			},
 			StarterCode: `type Page = "home" | "about" | "contact";
 
 const pageViews: ???<Page, number> = {
 	home: 1000,
 	about: 500,
 	contact: 200
+};`,
+			Solution: `type Page = "home" | "about" | "contact";
+
+const pageViews: Record<Page, number> = {
+	home: 1000,
+	about: 500,
+	contact: 200
 };`,
 			TestScript: `
-if (pageViews.home !== 1000) throw new Error("home page should have 1000 views");
-if (pageViews.about !== 500) throw new Error("about page should have 500 views");
-if (pageViews.contact !== 200) throw new Error("contact page should have 200 views");
+check("home page should have 1000 views", () => pageViews.home === 1000);
+check("about page should have 500 views", () => pageViews.about === 500);
+check("contact page should have 200 views", () => pageViews.contact === 200);
 `,
 			TypeAssertions: `
 // Should only allow keys of Page and values of number
@@ -1481,9 +2545,17 @@ type _Check = Assert<IsType<typeof pageViews, Record<Page, number>>>;
 		{
 			ID:          "utility-types-returntype",
 			title:       "Utility Types: `ReturnType`",
+			chapter:     "Utility Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"utility-types", "functions", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "The `ReturnType<T>` utility type constructs a type consisting of the return type of function T.",
 			info:        `I'll be honest, I haven't had a need for this one. But it seems cool! You can extract the return type of a function and use it elsewhere. Neat!`,
+			Hints: []string{
+				"You want the type of whatever getUser returns.",
+				"The utility type is `ReturnType`.",
+			},
 			StarterCode: `function getUser() {
 	return {
 		id: 1,
@@ -1493,10 +2565,20 @@ type _Check = Assert<IsType<typeof pageViews, Record<Page, number>>>;
 }
 
 type User = ???<typeof getUser>
+const user: User = getUser();`,
+			Solution: `function getUser() {
+	return {
+		id: 1,
+		username: "Shitou",
+		email: "shitou@example.com"
+	};
+}
+
+type User = ReturnType<typeof getUser>
 const user: User = getUser();`,
 			TestScript: `
-if (user.username !== "Shitou") throw new Error("username should be 'Shitou'");
-if (user.email !== "shitou@example.com") throw new Error("email should be 'shitou@example.com'");
+check("username should be 'Shitou'", () => user.username === "Shitou");
+check("email should be 'shitou@example.com'", () => user.email === "shitou@example.com");
 `,
 			TypeAssertions: `
 // User should be the return type of getUser
@@ -1505,17 +2587,27 @@ type _Check = Assert<IsType<User, ReturnType<typeof getUser>>>;
 		},
 		{
 			ID:          "utility-types-exclude",
+			TypeOnly:    true,
 			title:       "Utility Types: `Exclude`",
+			chapter:     "Utility Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "The `Exclude<T, U>` utility type constructs a type by excluding from T all union members that are assignable to U.",
 			info:        `This is like using ` + code.Render("Omit") + ` on a union type. It allows you to create a new type by excluding certain members from an existing union type.`,
+			Hints: []string{
+				"You want to remove some members from the union.",
+				"The utility type is `Exclude`.",
+			},
 			StarterCode: `type someType = string | number | boolean;
 type Excluded = ???<someType, string | boolean>;
 
 const value: Excluded = 123;`,
-			TestScript: `
-if (value !== 123) throw new Error("value should be 123");
-`,
+			Solution: `type someType = string | number | boolean;
+type Excluded = Exclude<someType, string | boolean>;
+
+const value: Excluded = 123;`,
 			TypeAssertions: `
 // Should exclude string and boolean from T, leaving only number
 type _Check = Assert<IsType<Excluded, number>>;
@@ -1523,20 +2615,194 @@ type _Check = Assert<IsType<Excluded, number>>;
 		},
 		{
 			ID:          "utility-types-extract",
+			TypeOnly:    true,
 			title:       "Utility Types: `Extract`",
+			chapter:     "Utility Types",
+			Level:       LevelAdvanced,
+			Tags:        []string{"utility-types", "generics"},
+			Minutes:     6,
 			Label:       "",
 			description: "The `Extract<T, U>` utility type constructs a type by extracting from T all union members that are assignable to U.",
 			info:        `This is the opposite of ` + code.Render("Exclude") + `. It allows you to create a new type by extracting ` + bold.Render("only the members from an existing union type") + ` that are assignable to another type. That's a verbose definition, but language is an imperfect medium.`,
+			Hints: []string{
+				"You want to keep only some members of the union.",
+				"The utility type is `Extract`.",
+			},
 			StarterCode: `type T = string | number | boolean;
 type Extracted = ???<T, string | boolean>;
 
 const value: Extracted = "hello";`,
-			TestScript: `
-if (value !== "hello") throw new Error("value should be 'hello'");
-`,
+			Solution: `type T = string | number | boolean;
+type Extracted = Extract<T, string | boolean>;
+
+const value: Extracted = "hello";`,
 			TypeAssertions: `
 // Should extract only string and boolean from T
 type _Check = Assert<IsType<Extracted, string | boolean>>;
+`,
+		},
+		{
+			ID:          "expect-error-readonly",
+			Kind:        KindExpectError,
+			title:       "Compiler Errors: readonly",
+			chapter:     "Compiler Errors",
+			Level:       LevelIntermediate,
+			Tags:        []string{"compiler-errors", "readonly"},
+			Minutes:     4,
+			Label:       "",
+			description: "Sometimes the lesson is what the compiler refuses to do",
+			info:        `Much of TypeScript's value is in the code it ` + bold.Render("rejects") + `. A ` + code.Render("// @ts-expect-error") + ` comment says "the next line should not compile". If it does compile, the comment itself becomes an error. Adding an error code, like ` + code.Render("TS2540") + `, pins down exactly which mistake you expect.`,
+			Hints: []string{
+				"Replace the blank with a line that changes something the compiler considers read-only.",
+				"Try assigning a new value to `sutra.title`.",
+			},
+			StarterCode: `const sutra = { title: "Heart Sutra", lines: 14 } as const;
+
+// Write a line the compiler rejects: reassign one of sutra's properties.
+// @ts-expect-error TS2540
+???`,
+			Solution: `const sutra = { title: "Heart Sutra", lines: 14 } as const;
+
+// Write a line the compiler rejects: reassign one of sutra's properties.
+// @ts-expect-error TS2540
+sutra.title = "Diamond Sutra";`,
+			TestScript: `
+if (sutra.lines !== 14) throw new Error("sutra.lines should be 14");
+`,
+			TypeAssertions: `
+// sutra's properties should be readonly literals
+type _Check = Assert<IsType<typeof sutra, { readonly title: "Heart Sutra"; readonly lines: 14 }>>;
+`,
+		},
+		{
+			ID:          "strict-null-checks",
+			title:       "Strictness: strictNullChecks",
+			chapter:     "Strictness",
+			Level:       LevelIntermediate,
+			Tags:        []string{"strictness", "null"},
+			Minutes:     4,
+			Label:       "",
+			description: "With `strictNullChecks`, `null` must be declared where it can appear",
+			info:        `Without ` + kw.Render("strictNullChecks") + `, ` + code.Render("null") + ` and ` + code.Render("undefined") + ` quietly belong to every type, so a function that says it returns a ` + code.Render("string") + ` may hand you ` + code.Render("null") + `. This koan is compiled with ` + code.Render("strict") + ` turned on, so the compiler holds you to what you declare.`,
+			Hints: []string{
+				"findMonk returns the name, or null when it isn't Linji.",
+				"Declare both possibilities with a union: `string | null`.",
+			},
+			StarterCode: `function findMonk(name: string): ??? {
+  return name === "Linji" ? name : null;
+}
+
+const found = findMonk("Linji");`,
+			Solution: `function findMonk(name: string): string | null {
+  return name === "Linji" ? name : null;
+}
+
+const found = findMonk("Linji");`,
+			TestScript: `
+check('findMonk("Linji") should return "Linji"', () => found === "Linji");
+check('findMonk("Chris") should return null', () => findMonk("Chris") === null);
+`,
+			TypeAssertions: `
+// findMonk should admit that it may return null
+type _Check = Assert<IsType<ReturnType<typeof findMonk>, string | null>>;
+`,
+			CompilerOptions: map[string]any{"strict": true},
+		},
+		{
+			ID:          "modules-import",
+			title:       "Modules: import",
+			chapter:     "Modules",
+			Level:       LevelIntermediate,
+			Tags:        []string{"modules"},
+			Minutes:     4,
+			Label:       "",
+			description: "Types can be imported from another file, just like values",
+			info:        `A file with a top-level ` + kw.Render("import") + ` or ` + kw.Render("export") + ` is a ` + bold.Render("module") + `: its names stay private unless it exports them. ` + code.Render("monks.ts") + ` exports both an interface and a function, and one ` + code.Render("import") + ` brings in either kind. Press ` + code.Render("F4") + ` to look at it; it is read-only.`,
+			Hints: []string{
+				"Look at what koan.ts already imports from ./monks.",
+				"zhaozhou should be a `Monk`.",
+			},
+			StarterCode: `import { Monk, greet } from "./monks";
+
+const zhaozhou: ??? = { name: "Zhaozhou" };
+const greeting = greet(zhaozhou);`,
+			Solution: `import { Monk, greet } from "./monks";
+
+const zhaozhou: Monk = { name: "Zhaozhou" };
+const greeting = greet(zhaozhou);`,
+			Files: []SourceFile{
+				{
+					Name: "monks.ts",
+					Code: `export interface Monk {
+  name: string;
+}
+
+export function greet(monk: Monk): string {
+  return "Greetings, " + monk.name;
+}`,
+					ReadOnly: true,
+				},
+			},
+			TestScript: `
+if (greeting !== "Greetings, Zhaozhou") throw new Error("greeting should be 'Greetings, Zhaozhou', got: " + greeting);
+`,
+			TypeAssertions: `
+// zhaozhou should be a Monk
+type _Check = Assert<IsType<typeof zhaozhou, Monk>>;
+`,
+		},
+		{
+			ID:          "modules-augmentation",
+			title:       "Modules: augmentation",
+			chapter:     "Modules",
+			Level:       LevelAdvanced,
+			Tags:        []string{"modules", "declaration-files", "interfaces", "declaration-merging"},
+			Minutes:     6,
+			Label:       "",
+			description: "A declaration file can add to an interface exported by another module",
+			info:        `Interfaces with the same name are ` + bold.Render("merged") + `, even across files. Inside ` + code.Render("declare module \"./monks\"") + `, a declaration file can reopen the module's ` + code.Render("Monk") + ` interface and add members to it, without touching ` + code.Render("monks.ts") + `. Press ` + code.Render("F4") + ` to switch to ` + code.Render("monks-school.d.ts") + `.`,
+			Hints: []string{
+				"koan.ts gives linji a property that Monk doesn't have yet. Add it in monks-school.d.ts.",
+				"Inside the `interface Monk` block, declare `school: string;`.",
+			},
+			StarterCode: `import { Monk } from "./monks";
+
+const linji: Monk = { name: "Linji", school: "Rinzai" };`,
+			Solution: `import { Monk } from "./monks";
+
+const linji: Monk = { name: "Linji", school: "Rinzai" };`,
+			Files: []SourceFile{
+				{
+					Name: "monks.ts",
+					Code: `export interface Monk {
+  name: string;
+}`,
+					ReadOnly: true,
+				},
+				{
+					Name: "monks-school.d.ts",
+					Code: `export {};
+
+declare module "./monks" {
+  interface Monk {
+    ???
+  }
+}`,
+					Solution: `export {};
+
+declare module "./monks" {
+  interface Monk {
+    school: string;
+  }
+}`,
+				},
+			},
+			TestScript: `
+if (linji.school !== "Rinzai") throw new Error("linji.school should be 'Rinzai'");
+`,
+			TypeAssertions: `
+// Monk should now have a school
+type _Check = Assert<IsType<Monk["school"], string>>;
 `,
 		},
 	}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// legacyCatalogV0 is the order of Exercises() when state.json was written
+// as version 0, which keyed everything by position in this list. It must
+// never change: it is only used to map old saves onto exercise IDs.
+var legacyCatalogV0 = []string{
+	"primitives-string",
+	"primitives-number",
+	"primitives-boolean",
+	"primitives-bigint",
+	"primitives-symbol",
+	"unique-symbol",
+	"primitives-any",
+	"primitives-null",
+	"primitives-undefined",
+	"arrays",
+	"readonly-arrays",
+	"parameter-type-annotations-string",
+	"parameter-type-annotations-number",
+	"parameter-type-annotations-boolean",
+	"parameter-type-annotations-any",
+	"parameter-type-annotations-array",
+	"return-type-annotations-string",
+	"return-type-annotations-number",
+	"return-type-annotations-boolean",
+	"return-type-annotations-any",
+	"return-type-annotations-void",
+	"anonymous-functions",
+	"object-types",
+	"object-types-readonly",
+	"optional-properties",
+	"union-types",
+	"union-types-narrowing",
+	"union-types-nullable",
+	"nullish-coalescing",
+	"optional-chaining",
+	"as-const",
+	"discriminated-unions",
+	"type-aliases-object-types",
+	"type-aliases-union-types",
+	"type-aliases-extending",
+	"type-aliases-immutability",
+	"interfaces",
+	"interfaces-extending",
+	"interfaces-redefining",
+	"tuples",
+	"readonly-tuples",
+	"promises",
+	"type-assertions-as",
+	"literal-types",
+	"literal-types-unions-of-strings",
+	"literal-types-unions-of-numbers",
+	"literal-types-as-literal",
+	"enums-number",
+	"enums-string",
+	"type-guards-typeof",
+	"narrowing-in",
+	"type-predicates-is",
+	"narrowing-never",
+	"index-signatures",
+	"index-signature-unknown",
+	"intersection-types",
+	"generics-type-alias",
+	"generics-function",
+	"generics-constraints",
+	"generics-defaults",
+	"keyof",
+	"mapped-types",
+	"mapped-type-remove-optional",
+	"mapped-type-remove-readonly",
+	"utility-types-partial",
+	"utility-types-required",
+	"utility-pick",
+	"utility-types-omit",
+	"utility-types-readonly",
+	"utility-types-record",
+	"utility-types-returntype",
+	"utility-types-exclude",
+	"utility-types-extract",
+}
+
+// stateV0 is the index-keyed layout of state.json before versioning.
+type stateV0 struct {
+	SelectedIndex int            `json:"selected_index"`
+	Solutions     map[int]string `json:"solutions"`
+	Completed     map[int]bool   `json:"completed"`
+}
+
+// migrateStateV0 maps an index-keyed save onto exercise IDs. The original
+// file is kept next to it as state.v0.json so nothing is lost if the
+// mapping is wrong, and the migrated state is written back straight away so
+// this only ever happens once. Entries past the end of the legacy catalog
+// can't be placed and are dropped.
+func migrateStateV0(path string, data []byte) (PersistentState, error) {
+	var old stateV0
+	state := PersistentState{
+		Version:   stateVersion,
+		Solutions: make(map[string]string),
+		Completed: make(map[string]bool),
+	}
+	if err := json.Unmarshal(data, &old); err != nil {
+		return state, fmt.Errorf("read version 0 state: %w", err)
+	}
+
+	idAt := func(i int) (string, bool) {
+		if i < 0 || i >= len(legacyCatalogV0) {
+			return "", false
+		}
+		return legacyCatalogV0[i], true
+	}
+	for i, code := range old.Solutions {
+		if id, ok := idAt(i); ok {
+			state.Solutions[id] = code
+		}
+	}
+	for i, done := range old.Completed {
+		if id, ok := idAt(i); ok && done {
+			state.Completed[id] = true
+		}
+	}
+	if id, ok := idAt(old.SelectedIndex); ok {
+		state.SelectedID = id
+	}
+
+	backup := strings.TrimSuffix(path, ".json") + ".v0.json"
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return state, fmt.Errorf("back up version 0 state: %w", err)
+	}
+	return state, SaveState(state)
+}
@@ -0,0 +1,479 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Koan packs let people add their own exercises without rebuilding.
+// A pack is a directory under ~/.ts-koans/packs/<name>/ holding one or more
+// Markdown files. Each koan in a file starts with a front matter block,
+// followed by free text (the info panel) and fenced code blocks whose info
+// string names the field they fill:
+//
+//	---
+//	id: acme-result
+//	title: Acme: Result<T>
+//	chapter: Acme Services
+//	description: Our services return a Result<T> instead of throwing
+//	hint: A Result is generic over the type of its value.
+//	hint: Try Result<number>.
+//	level: beginner
+//	tags: errors, generics
+//	minutes: 3
+//	---
+//	Info text. `inline code` and **bold** are styled like the built-ins.
+//
+//	```ts starter
+//	const r: ??? = { ok: true, value: 1 };
+//	```
+//
+//	```ts solution
+//	const r: Result<number> = { ok: true, value: 1 };
+//	```
+//
+//	```ts assertions
+//	type _Check = Assert<IsType<typeof r, Result<number>>>;
+//	```
+//
+//	```js test
+//	if (!r.ok) throw new Error("r should be ok");
+//	```
+//
+//	```json compilerOptions
+//	{"strict": true}
+//	```
+//
+// Koans about modules can have more files, in blocks named with a file
+// name: `file <name>` for an editable file, `readonly-file <name>` for one
+// the learner can only read, and `solution-file <name>` for the reference
+// answer to an editable file above it.
+//
+//	```ts readonly-file monks.ts
+//	export interface Monk { name: string }
+//	```
+//
+// Files are read in name order and packs in directory order, and the koans
+// are appended after the built-in catalog. Koans without a chapter are put
+// in a chapter named after their pack.
+
+// PackError is a validation problem in a pack file. Line is 1-based, or 0
+// when the problem concerns the whole file.
+type PackError struct {
+	Path string
+	Line int
+	Msg  string
+}
+
+func (e PackError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.Path, e.Line, e.Msg)
+}
+
+var (
+	packIDPattern   = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	packCodePattern = regexp.MustCompile("`([^`]+)`")
+	packBoldPattern = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	packFrontMatter = "---"
+	// packFrontMatterKey is what the first line of front matter starts
+	// with, e.g. "id:". A "---" followed by anything else is a horizontal
+	// rule in the koan's text.
+	packFrontMatterKey = regexp.MustCompile(`^[a-z][a-z-]*\s*:`)
+	packFence          = "```"
+	packBlockFields    = []string{"starter", "solution", "assertions", "test", "compilerOptions"}
+	packFileFields     = []string{"file", "readonly-file", "solution-file"}
+	packFileName       = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*\.tsx?$`)
+	// Names the runner uses for its own files in the build directory.
+	packReservedFiles = []string{"typecheck.ts", "run.ts"}
+	packFrontMatterKV = []string{"id", "title", "chapter", "description", "hint", "kind", "type-only", "esm", "level", "tags", "minutes"}
+)
+
+// startsFrontMatter reports whether the lines after a "---" are front
+// matter: the first one that isn't blank looks like `key: value`.
+func startsFrontMatter(rest []string) bool {
+	for _, line := range rest {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return packFrontMatterKey.MatchString(trimmed)
+		}
+	}
+	return false
+}
+
+// PacksDir is where koan packs are looked up.
+func PacksDir() string {
+	return filepath.Join(getConfigDir(), "packs")
+}
+
+// Catalog returns the built-in exercises followed by every exercise found in
+// PacksDir, along with any validation errors from the packs. Invalid koans
+// are left out of the catalog but always reported.
+func Catalog() ([]Exercise, []error) {
+	exs := Exercises()
+	taken := make(map[string]string, len(exs))
+	for _, ex := range exs {
+		taken[ex.ID] = "the built-in catalog"
+	}
+	packed, errs := LoadPacks(PacksDir(), taken)
+	return append(exs, packed...), errs
+}
+
+// LoadPacks reads every pack under dir. taken maps exercise IDs that are
+// already in use to a description of where they came from; it is updated
+// with the IDs of the koans that get loaded. A missing dir is not an error.
+func LoadPacks(dir string, taken map[string]string) ([]Exercise, []error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, []error{PackError{Path: dir, Msg: err.Error()}}
+	}
+
+	var exs []Exercise
+	var errs []error
+	for _, pack := range entries {
+		if !pack.IsDir() {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, pack.Name(), "*.md"))
+		if err != nil {
+			errs = append(errs, PackError{Path: filepath.Join(dir, pack.Name()), Msg: err.Error()})
+			continue
+		}
+		sort.Strings(files)
+		for _, path := range files {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, PackError{Path: path, Msg: err.Error()})
+				continue
+			}
+			koans, fileErrs := parsePackFile(path, data, pack.Name())
+			errs = append(errs, fileErrs...)
+			for _, k := range koans {
+				if where, ok := taken[k.ex.ID]; ok {
+					errs = append(errs, PackError{Path: path, Line: k.line, Msg: fmt.Sprintf("duplicate id %q, already defined in %s", k.ex.ID, where)})
+					continue
+				}
+				taken[k.ex.ID] = fmt.Sprintf("%s:%d", path, k.line)
+				exs = append(exs, k.ex)
+			}
+		}
+	}
+	return exs, errs
+}
+
+// packKoan is a parsed koan along with the line its front matter starts on.
+type packKoan struct {
+	ex   Exercise
+	line int
+	info []string
+	seen map[string]int // field name -> line it was set on
+	bad  bool
+}
+
+// parsePackFile parses one pack file. Koans with errors are dropped from the
+// result; each problem is reported with its line number.
+func parsePackFile(path string, data []byte, defaultChapter string) ([]packKoan, []error) {
+	var koans []packKoan
+	var errs []error
+	var cur *packKoan
+
+	fail := func(line int, format string, args ...any) {
+		errs = append(errs, PackError{Path: path, Line: line, Msg: fmt.Sprintf(format, args...)})
+		if cur != nil {
+			cur.bad = true
+		}
+	}
+	finish := func() {
+		if cur == nil {
+			return
+		}
+		if cur.ex.ID == "" {
+			fail(cur.line, "koan is missing an id")
+		}
+		if cur.ex.title == "" {
+			fail(cur.line, "koan %q is missing a title", cur.ex.ID)
+		}
+		if _, ok := cur.seen["starter"]; !ok {
+			fail(cur.line, "koan %q has no starter block", cur.ex.ID)
+		}
+		if cur.ex.TypeAssertions == "" && cur.ex.TestScript == "" {
+			fail(cur.line, "koan %q has no assertions or test block, so it can never fail", cur.ex.ID)
+		}
+		if cur.ex.TypeOnly && cur.ex.TestScript != "" {
+			fail(cur.line, "koan %q is type-only, so its test block would never run", cur.ex.ID)
+		}
+		if cur.ex.chapter == "" {
+			cur.ex.chapter = defaultChapter
+		}
+		cur.ex.info = renderInlineMarkup(strings.TrimSpace(strings.Join(cur.info, "\n")))
+		if !cur.bad {
+			koans = append(koans, *cur)
+		}
+		cur = nil
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		fail(len(lines)+1, "%v", err)
+	}
+	inFrontMatter := false
+	fenceField := ""
+	fenceFile := ""
+	fenceStart := 0
+	var fenceLines []string
+
+	for i, line := range lines {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case fenceStart > 0:
+			if trimmed != packFence {
+				fenceLines = append(fenceLines, line)
+				continue
+			}
+			if cur != nil && fenceField != "" {
+				if err := setPackBlock(&cur.ex, fenceField, fenceFile, strings.Join(fenceLines, "\n")); err != nil {
+					fail(fenceStart, "%s block: %v", fenceField, err)
+				}
+			}
+			fenceStart = 0
+			fenceLines = nil
+
+		case inFrontMatter:
+			if trimmed == packFrontMatter {
+				inFrontMatter = false
+				continue
+			}
+			if trimmed == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				fail(lineNum, "expected `key: value` in front matter, got %q", trimmed)
+				continue
+			}
+			key = strings.TrimSpace(key)
+			value = strings.TrimSpace(value)
+			if key == "hint" {
+				// Hints are ordered and may repeat.
+				cur.ex.Hints = append(cur.ex.Hints, value)
+				continue
+			}
+			if prev, dup := cur.seen[key]; dup {
+				fail(lineNum, "%s is already set on line %d", key, prev)
+				continue
+			}
+			cur.seen[key] = lineNum
+			switch key {
+			case "id":
+				if !packIDPattern.MatchString(value) {
+					fail(lineNum, "id %q must be lowercase letters, digits and dashes", value)
+				}
+				cur.ex.ID = value
+			case "title":
+				cur.ex.title = value
+			case "chapter":
+				cur.ex.chapter = value
+			case "description":
+				cur.ex.description = value
+			case "kind":
+				switch value {
+				case "type-check":
+					cur.ex.Kind = KindTypeCheck
+				case "expect-error":
+					cur.ex.Kind = KindExpectError
+				default:
+					fail(lineNum, "unknown kind %q (expected type-check or expect-error)", value)
+				}
+			case "type-only":
+				typeOnly, err := strconv.ParseBool(value)
+				if err != nil {
+					fail(lineNum, "type-only must be true or false, got %q", value)
+				}
+				cur.ex.TypeOnly = typeOnly
+			case "esm":
+				esm, err := strconv.ParseBool(value)
+				if err != nil {
+					fail(lineNum, "esm must be true or false, got %q", value)
+				}
+				cur.ex.ESM = esm
+			case "level":
+				level, ok := ParseLevel(value)
+				if !ok {
+					fail(lineNum, "unknown level %q (expected 1-3 or beginner, intermediate, advanced)", value)
+				}
+				cur.ex.Level = level
+			case "tags":
+				for _, tag := range strings.Split(value, ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						cur.ex.Tags = append(cur.ex.Tags, tag)
+					}
+				}
+			case "minutes":
+				minutes, err := strconv.Atoi(value)
+				if err != nil || minutes <= 0 {
+					fail(lineNum, "minutes must be a positive whole number, got %q", value)
+				}
+				cur.ex.Minutes = minutes
+			default:
+				fail(lineNum, "unknown front matter key %q (expected one of %s)", key, strings.Join(packFrontMatterKV, ", "))
+			}
+
+		case trimmed == packFrontMatter && startsFrontMatter(lines[i+1:]):
+			finish()
+			cur = &packKoan{line: lineNum, seen: make(map[string]int)}
+			inFrontMatter = true
+
+		case strings.HasPrefix(trimmed, packFence):
+			fenceStart = lineNum
+			fenceField = ""
+			fenceFile = ""
+			words := strings.Fields(strings.TrimPrefix(trimmed, packFence))
+			if cur == nil {
+				fail(lineNum, "code block before the first front matter block")
+				continue
+			}
+			if len(words) == 0 {
+				fail(lineNum, "code block needs a field name (one of %s)", strings.Join(packBlockFields, ", "))
+				continue
+			}
+			field, key := words[len(words)-1], words[len(words)-1]
+			if len(words) >= 2 && isPackFileField(words[len(words)-2]) {
+				field = words[len(words)-2]
+				fenceFile = words[len(words)-1]
+				key = field + " " + fenceFile
+				if err := checkPackFileName(fenceFile); err != nil {
+					fail(lineNum, "%v", err)
+					continue
+				}
+			} else if !isPackBlockField(field) {
+				fail(lineNum, "unknown code block %q (expected one of %s, or %s followed by a file name)", field, strings.Join(packBlockFields, ", "), strings.Join(packFileFields, ", "))
+				continue
+			}
+			if prev, dup := cur.seen[key]; dup {
+				fail(lineNum, "%s block is already set on line %d", key, prev)
+				continue
+			}
+			cur.seen[key] = lineNum
+			fenceField = field
+
+		default:
+			if cur == nil {
+				if trimmed != "" {
+					fail(lineNum, "text before the first front matter block")
+				}
+				continue
+			}
+			cur.info = append(cur.info, line)
+		}
+	}
+	if inFrontMatter && cur != nil {
+		fail(cur.line, "front matter is never closed with ---")
+	}
+	if fenceStart > 0 {
+		fail(fenceStart, "code block is never closed with ```")
+	}
+	finish()
+	if len(koans) == 0 && len(errs) == 0 {
+		errs = append(errs, PackError{Path: path, Msg: "no koans found"})
+	}
+	return koans, errs
+}
+
+func isPackBlockField(field string) bool {
+	for _, f := range packBlockFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func isPackFileField(field string) bool {
+	for _, f := range packFileFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPackFileName makes sure a file block's name is a relative .ts path
+// that doesn't clash with the runner's own files.
+func checkPackFileName(name string) error {
+	if !packFileName.MatchString(name) || strings.Contains(name, "..") {
+		return fmt.Errorf("file name %q must be a relative path ending in .ts or .tsx", name)
+	}
+	for _, r := range packReservedFiles {
+		if name == r {
+			return fmt.Errorf("file name %q is reserved", name)
+		}
+	}
+	return nil
+}
+
+// setPackBlock fills the exercise field named by a code block. name is the
+// file name for file blocks.
+func setPackBlock(ex *Exercise, field, name, body string) error {
+	switch field {
+	case "file", "readonly-file":
+		for _, f := range ex.Files {
+			if f.Name == name {
+				return fmt.Errorf("file %q is already defined", name)
+			}
+		}
+		ex.Files = append(ex.Files, SourceFile{Name: name, Code: body, ReadOnly: field == "readonly-file"})
+	case "solution-file":
+		for i, f := range ex.Files {
+			if f.Name != name {
+				continue
+			}
+			if f.ReadOnly {
+				return fmt.Errorf("file %q is read-only, so it has no solution", name)
+			}
+			ex.Files[i].Solution = body
+			return nil
+		}
+		return fmt.Errorf("no file block named %q above it", name)
+	case "starter":
+		ex.StarterCode = body
+	case "solution":
+		ex.Solution = body
+	case "assertions":
+		ex.TypeAssertions = "\n" + body + "\n"
+	case "test":
+		ex.TestScript = "\n" + body + "\n"
+	case "compilerOptions":
+		if err := json.Unmarshal([]byte(body), &ex.CompilerOptions); err != nil {
+			return fmt.Errorf("expected a JSON object of tsconfig compiler options: %w", err)
+		}
+	}
+	return nil
+}
+
+// renderInlineMarkup styles `code` and **bold** spans the same way the
+// built-in info texts do.
+func renderInlineMarkup(text string) string {
+	text = packCodePattern.ReplaceAllStringFunc(text, func(s string) string {
+		return code.Render(strings.Trim(s, "`"))
+	})
+	return packBoldPattern.ReplaceAllStringFunc(text, func(s string) string {
+		return bold.Render(strings.Trim(s, "*"))
+	})
+}
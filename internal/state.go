@@ -7,21 +7,37 @@ import (
 	"path/filepath"
 )
 
+// stateVersion is the schema version written to state.json. Files without a
+// version field are version 0, which keyed progress by catalog position.
+const stateVersion = 1
+
 type PersistentState struct {
-	SelectedIndex int            `json:"selected_index"`
-	Solutions     map[int]string `json:"solutions"`
-	Completed     map[int]bool   `json:"completed"`
+	Version    int               `json:"version"`
+	SelectedID string            `json:"selected_id"`
+	Solutions  map[string]string `json:"solutions"`  // exercise ID -> code
+	Completed  map[string]bool   `json:"completed"`  // exercise ID -> solved
+	HintsUsed  map[string]int    `json:"hints_used"` // exercise ID -> hints revealed
+	Unaided    map[string]bool   `json:"unaided"`    // exercise ID -> solved before any hint
+	// FileSolutions holds the learner's copies of a multi-file koan's
+	// editable files: exercise ID -> file name -> code.
+	FileSolutions map[string]map[string]string `json:"file_solutions,omitempty"`
 }
 
-func getStateFilePath() string {
+// getConfigDir returns ~/.ts-koans, creating it if needed.
+func getConfigDir() string {
 	usr, _ := user.Current()
 	configDir := filepath.Join(usr.HomeDir, ".ts-koans")
 	os.MkdirAll(configDir, 0700)
-	return filepath.Join(configDir, "state.json")
+	return configDir
+}
+
+func getStateFilePath() string {
+	return filepath.Join(getConfigDir(), "state.json")
 }
 
 func SaveState(state PersistentState) error {
 	path := getStateFilePath()
+	state.Version = stateVersion
 	data, _ := json.MarshalIndent(state, "", "  ")
 	return os.WriteFile(path, data, 0600)
 }
@@ -31,12 +47,21 @@ func LoadState() (PersistentState, error) {
 	path := getStateFilePath()
 	data, err := os.ReadFile(path)
 	if err != nil {
-		state.Solutions = make(map[int]string)
+		state.Solutions = make(map[string]string)
 		return state, nil
 	}
-	json.Unmarshal(data, &state)
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	json.Unmarshal(data, &header)
+	if header.Version == 0 {
+		state, err = migrateStateV0(path, data)
+	} else {
+		json.Unmarshal(data, &state)
+	}
 	if state.Solutions == nil {
-		state.Solutions = make(map[int]string)
+		state.Solutions = make(map[string]string)
 	}
-	return state, nil
+	return state, err
 }
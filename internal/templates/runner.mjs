@@ -1,17 +1,355 @@
-import { readFileSync } from "fs";
-import vm from "vm";
+import { readFileSync, existsSync, writeSync } from "node:fs";
+import net from "node:net";
+import path from "node:path";
+import { pathToFileURL } from "node:url";
+import util from "node:util";
+import vm from "node:vm";
 
-const combined = readFileSync("./run.js", "utf8");
+// Results are reported as JSON lines on a channel of their own, named by
+// TSKOANS_EVENTS: "fd:3" for a file descriptor, or "tcp:host:port" on
+// Windows. Without it (when run by hand) they go to stdout.
+const eventTarget = process.env.TSKOANS_EVENTS || "";
+let emit = (event) => process.stdout.write(JSON.stringify(event) + "\n");
+let closeEvents = async () => {};
+if (eventTarget.startsWith("fd:")) {
+  const fd = Number(eventTarget.slice(3));
+  emit = (event) => writeSync(fd, JSON.stringify(event) + "\n");
+} else if (eventTarget.startsWith("tcp:")) {
+  const address = eventTarget.slice(4);
+  const sep = address.lastIndexOf(":");
+  const socket = net.connect(Number(address.slice(sep + 1)), address.slice(0, sep));
+  socket.on("error", () => { process.exitCode = 1; });
+  emit = (event) => socket.write(JSON.stringify(event) + "\n");
+  closeEvents = () => new Promise((resolve) => socket.end(resolve));
+}
+
+// --- Sandbox ---
+//
+// Koan code runs in a vm context with no process, require (other than for
+// the koan's own files), import(), eval or new Function. vm is no security
+// boundary on its own, so node itself runs under its permission model as
+// well (see sandbox.go). Inside the context, koan code must never get hold
+// of an object from this side: through any of them, `x.constructor
+// .constructor("return process")()` reaches the real process. So the test
+// API, console and module system are built inside the context by BOOTSTRAP,
+// which talks to this side through `bridge`, a closure it keeps to itself.
+// The bridge only ever hands back strings, numbers and functions compiled
+// in the context, and never throws.
+
+const context = vm.createContext({}, { codeGeneration: { strings: false, wasm: false } });
+
+// import() is refused with an error made in the context: node's own error
+// for a missing import callback comes from this side.
+const contextError = vm.runInContext("(message) => new Error(message)", context);
+function importModuleDynamically() {
+  throw contextError("🔒 import() is not available to koan code");
+}
+
+// The koan gets a console that records each call, in order, with its level
+// and arguments. Strings are kept as they are and everything else goes
+// through util.inspect, as console.log itself would. A runaway loop can't
+// flood the output: past maxConsoleCalls, calls are dropped.
+const maxConsoleCalls = 1000;
+let consoleCalls = 0;
+
+function inspect(arg) {
+  if (typeof arg === "string") return arg;
+  try {
+    // No custom inspectors: they would be handed objects from this side.
+    return util.inspect(arg, { depth: 4, customInspect: false, showProxy: true, getters: false });
+  } catch {
+    return "(a value that can't be printed)";
+  }
+}
+
+// restricted explains errors that come from the sandbox's limits.
+function restricted(message) {
+  if (/^(process|global|Buffer|__dirname|__filename|setImmediate) is not defined/.test(message)) {
+    return `🔒 ${message}: koan code runs in a sandbox without Node's globals`;
+  }
+  if (/Code generation from strings disallowed/.test(message)) {
+    return "🔒 eval() and new Function() are not available to koan code";
+  }
+  if (/dynamic import callback/.test(message)) {
+    return "🔒 import() is not available to koan code";
+  }
+  if (/ERR_ACCESS_DENIED|Access to this API has been restricted/.test(message)) {
+    return `🔒 ${message} (blocked by Node's permission model)`;
+  }
+  return message;
+}
+
+const names = [];
+const pending = new Map();
+let mainError = null;
+
+const bridge = Object.freeze({
+  log(level, args) {
+    try {
+      consoleCalls++;
+      if (consoleCalls > maxConsoleCalls) {
+        if (consoleCalls === maxConsoleCalls + 1) {
+          emit({ event: "console", level: "warn", args: [`(more than ${maxConsoleCalls} console calls; the rest are left out)`] });
+        }
+        return;
+      }
+      const formatted = [];
+      for (let i = 0; i < args.length; i++) formatted.push(inspect(args[i]));
+      emit({ event: "console", level: String(level), args: formatted });
+    } catch {
+      // Nothing may be thrown into the context.
+    }
+  },
+  register(name) {
+    names.push(typeof name === "string" ? name : "(unnamed test)");
+  },
+  // resolve returns the koan file that spec, required or imported from the
+  // koan file from, names, or "" if there is none. Only the koan's own
+  // files, next to run.js, can be loaded.
+  resolve(from, spec) {
+    if (typeof from !== "string" || typeof spec !== "string" || !spec.startsWith(".")) return "";
+    const file = path.resolve(path.dirname(from), spec.endsWith(".js") ? spec : spec + ".js");
+    const rel = path.relative(process.cwd(), file);
+    if (rel.startsWith("..") || path.isAbsolute(rel) || !existsSync(file)) return "";
+    return rel;
+  },
+  // compile returns the CommonJS wrapper for a koan file, or why it can't.
+  compile(file) {
+    try {
+      return vm.compileFunction(readFileSync(String(file), "utf8"), ["exports", "require", "module"], {
+        filename: String(file),
+        parsingContext: context,
+        importModuleDynamically,
+      });
+    } catch (err) {
+      return `Cannot load ${file}: ${err && err.message}`;
+    }
+  },
+  mainDone(message) {
+    mainError = typeof message === "string" ? restricted(message) : null;
+  },
+  caseDone(i, message) {
+    const resolve = pending.get(i);
+    pending.delete(i);
+    if (resolve) resolve(typeof message === "string" ? restricted(message) : undefined);
+  },
+});
+
+const BOOTSTRAP = `(function (bridge) {
+  "use strict";
+  const { Error, Map, Object, Reflect, String } = globalThis;
+
+  function describe(err) {
+    try {
+      return err !== null && typeof err === "object" && "message" in err ? String(err.message) : String(err);
+    } catch {
+      return "(an error that can't be printed)";
+    }
+  }
+
+  // Tests can be split into named cases, all of which run even when one fails:
+  //   test(name, fn)   fails if fn throws, or if the promise it returns rejects
+  //   check(name, fn)  fails if fn returns something falsy
+  // A script without cases passes unless it throws.
+  const cases = [];
+  function test(name, fn) {
+    name = String(name);
+    cases[cases.length] = fn;
+    bridge.register(name);
+  }
+  function check(name, fn) {
+    test(name, () => {
+      if (!fn()) throw new Error(String(name));
+    });
+  }
+
+  const console = {};
+  for (const level of ["log", "info", "warn", "error", "debug"]) {
+    console[level] = (...args) => bridge.log(level, args);
+  }
 
-// Optionally: set up a basic sandbox (exports/global, etc.)
-const sandbox = { exports: {}, module: { exports: {}}, console };
-const context = vm.createContext(sandbox);
+  // Multi-file koans compile to several CommonJS modules next to run.js.
+  const modules = new Map();
+  function load(file) {
+    if (modules.has(file)) return modules.get(file).exports;
+    const module = { exports: {} };
+    modules.set(file, module);
+    const wrapper = bridge.compile(file);
+    if (typeof wrapper === "string") throw new Error(wrapper);
+    const require = (spec) => {
+      const target = bridge.resolve(file, String(spec));
+      if (target === "") throw new Error("Cannot require \\"" + spec + "\\": only the koan's own files can be imported");
+      return load(target);
+    };
+    Reflect.apply(wrapper, module.exports, [module.exports, require, module]);
+    return module.exports;
+  }
+
+  function done(i) {
+    return [() => bridge.caseDone(i), (err) => bridge.caseDone(i, describe(err))];
+  }
+
+  Object.defineProperty(globalThis, "__koan", { value: Object.freeze({
+    main() {
+      try {
+        load("run.js");
+        bridge.mainDone();
+      } catch (err) {
+        bridge.mainDone(describe(err));
+      }
+    },
+    run(i) {
+      const [pass, fail] = done(i);
+      try {
+        const result = cases[i]();
+        if (result !== null && typeof result === "object" && typeof result.then === "function") {
+          result.then(pass, fail);
+        } else {
+          pass();
+        }
+      } catch (err) {
+        fail(err);
+      }
+    },
+  }) });
+  Object.defineProperty(globalThis, "test", { value: test });
+  Object.defineProperty(globalThis, "check", { value: check });
+  Object.defineProperty(globalThis, "console", { value: Object.freeze(console) });
+})`;
+
+vm.runInContext(BOOTSTRAP, context)(bridge);
+
+const scriptTimeout = 1000;
+const caseTimeout = 1000;
+// Together, the script and its cases get totalTimeout. Cases left when it
+// runs out fail without running, so a summary is always sent before
+// ts-koans gives up on the runner (nodeTimeout in main.go).
+const totalTimeout = 3500;
+const deadline = Date.now() + totalTimeout;
+
+function message(err) {
+  try {
+    return restricted(String(err && err.message ? err.message : err));
+  } catch {
+    return "(an error that can't be printed)";
+  }
+}
+
+// A promise that koan code rejects and never handles would otherwise crash
+// node, possibly after the summary has gone out. It fails the run instead.
+let unhandledRejection = null;
+process.on("unhandledRejection", (reason) => {
+  unhandledRejection ??= `A promise was rejected and never handled: ${message(reason)}`;
+  process.exitCode = 1;
+});
+
+// runCase runs one case for up to timeout ms, and returns undefined if it
+// passed, or why it failed.
+async function runCase(i, timeout) {
+  let timer;
+  const result = new Promise((resolve) => {
+    pending.set(i, resolve);
+    timer = setTimeout(() => resolve(`Timed out after ${timeout}ms`), timeout);
+  });
+  try {
+    // Run through the context so the timeout also stops synchronous loops.
+    vm.runInContext(`__koan.run(${i})`, context, { timeout });
+  } catch (err) {
+    // Only the timeout gets here; the case's own errors are caught inside.
+    bridge.caseDone(i, message(err));
+  }
+  try {
+    return await result;
+  } finally {
+    clearTimeout(timer);
+  }
+}
+
+// ES module koans compile to run.mjs and the modules it imports, which are
+// linked and evaluated here with vm.SourceTextModule (behind node's
+// --experimental-vm-modules). They run in the same context, with the same
+// test API, and can use top-level await and import.meta.
+async function runModule(main) {
+  if (typeof vm.SourceTextModule !== "function") {
+    throw new Error("ES module koans need Node.js, started with --experimental-vm-modules");
+  }
+  const modules = new Map();
+  const moduleFor = (file) => {
+    let module = modules.get(file);
+    if (!module) {
+      module = new vm.SourceTextModule(readFileSync(file, "utf8"), {
+        context,
+        identifier: file,
+        importModuleDynamically,
+        initializeImportMeta(meta) {
+          meta.url = pathToFileURL(path.resolve(file)).href;
+        },
+      });
+      modules.set(file, module);
+    }
+    return module;
+  };
+  const module = moduleFor(main);
+  await module.link((spec, referencing) => {
+    const target = bridge.resolve(referencing.identifier, spec);
+    if (target === "") throw new Error(`Cannot import "${spec}": only the koan's own files can be imported`);
+    return moduleFor(target);
+  });
+  // The timeout stops synchronous loops; the timer, a top-level await that
+  // never settles.
+  let timer;
+  const timedOut = new Promise((_, reject) => {
+    timer = setTimeout(() => reject(new Error(`Timed out after ${scriptTimeout}ms`)), scriptTimeout);
+  });
+  try {
+    await Promise.race([module.evaluate({ timeout: scriptTimeout }), timedOut]);
+  } finally {
+    clearTimeout(timer);
+  }
+}
+
+let passed = 0;
+let failed = 0;
+if (existsSync("run.mjs")) {
+  try {
+    await runModule("run.mjs");
+  } catch (err) {
+    mainError = message(err);
+  }
+} else {
+  try {
+    vm.runInContext("__koan.main()", context, { timeout: scriptTimeout });
+  } catch (err) {
+    mainError = message(err);
+  }
+}
+if (mainError !== null) {
+  emit({ event: "summary", passed, failed, error: mainError });
+  process.exitCode = 1;
+  await closeEvents();
+  process.exit();
+}
+
+for (let i = 0; i < names.length; i++) {
+  const name = names[i];
+  emit({ event: "start", name });
+  const left = deadline - Date.now();
+  const why = left > 0
+    ? await runCase(i, Math.min(caseTimeout, left))
+    : `Timed out after ${totalTimeout}ms for all the tests, before this case could run`;
+  if (why === undefined) {
+    passed++;
+    emit({ event: "pass", name });
+  } else {
+    failed++;
+    emit({ event: "fail", name, message: why });
+  }
+}
 
-try {
-  vm.runInContext(combined, context, { timeout: 1000 });
-  console.log("✅ All tests passed!")
-} catch (err) {
-  // Print clean error message
-  console.log("❌ Test failed:", err && err.message ? err.message : err);
-  process.exit(1);
+// Node reports unhandled rejections once the microtask queue is empty.
+await new Promise((resolve) => setImmediate(resolve));
+emit({ event: "summary", passed, failed, error: unhandledRejection ?? undefined });
+if (failed > 0 || unhandledRejection !== null) {
+  process.exitCode = 1;
 }
+await closeEvents();
@@ -0,0 +1,98 @@
+// Long-lived type checker for ts-koans, started once per session so that
+// TypeScript and its lib.*.d.ts files are only loaded and parsed once.
+//
+// Usage: node tsworker.cjs <path to the typescript package>
+//
+// Writes {"ready": true, "version": "..."} once TypeScript is loaded, then
+// reads one JSON request per line on stdin, {"id": 1, "project": "<tsconfig>"},
+// compiles the project as `tsc --project` would, and answers each with one
+// line on stdout: {"id": 1, "exitCode": 2, "output": "<tsc-style errors>"},
+// or {"id": 1, "error": "..."} if the compile itself blew up.
+"use strict";
+
+const path = require("path");
+const readline = require("readline");
+const ts = require(process.argv[2]);
+
+// Source files of the standard library, keyed by file name and language
+// version. Koan files change between runs and are always read fresh.
+const libCache = new Map();
+
+function createHost(options) {
+  const host = ts.createCompilerHost(options);
+  const libDir = path.resolve(path.dirname(ts.getDefaultLibFilePath(options)));
+  const getSourceFile = host.getSourceFile;
+  host.getSourceFile = (fileName, languageVersion, ...rest) => {
+    if (path.resolve(path.dirname(fileName)) !== libDir) {
+      return getSourceFile.call(host, fileName, languageVersion, ...rest);
+    }
+    const key = JSON.stringify([fileName, languageVersion]);
+    let file = libCache.get(key);
+    if (!file) {
+      file = getSourceFile.call(host, fileName, languageVersion, ...rest);
+      if (file) libCache.set(key, file);
+    }
+    return file;
+  };
+  return host;
+}
+
+function compile(project) {
+  const dir = path.dirname(project);
+  const formatHost = {
+    getCanonicalFileName: (f) => f,
+    getCurrentDirectory: () => dir,
+    getNewLine: () => "\n",
+  };
+
+  const configErrors = [];
+  const parsed = ts.getParsedCommandLineOfConfigFile(project, {}, {
+    ...ts.sys,
+    onUnRecoverableConfigFileDiagnostic: (d) => configErrors.push(d),
+  });
+  if (!parsed) {
+    return { exitCode: 1, output: ts.formatDiagnostics(configErrors, formatHost) };
+  }
+
+  const program = ts.createProgram({
+    rootNames: parsed.fileNames,
+    options: parsed.options,
+    host: createHost(parsed.options),
+    configFileParsingDiagnostics: parsed.errors,
+  });
+  const diagnostics = [...parsed.errors, ...ts.getPreEmitDiagnostics(program)];
+  let emitSkipped = true;
+  if (!parsed.options.noEmit) {
+    const result = program.emit();
+    diagnostics.push(...result.diagnostics);
+    emitSkipped = result.emitSkipped;
+  }
+
+  const sorted = ts.sortAndDeduplicateDiagnostics(diagnostics);
+  let exitCode = 0;
+  if (sorted.length > 0) {
+    // The same codes tsc exits with.
+    exitCode = emitSkipped ? 1 : 2;
+  }
+  return { exitCode, output: ts.formatDiagnostics(sorted, formatHost) };
+}
+
+process.stdout.write(JSON.stringify({ ready: true, version: ts.version }) + "\n");
+
+const rl = readline.createInterface({ input: process.stdin });
+rl.on("line", (line) => {
+  let req;
+  try {
+    req = JSON.parse(line);
+  } catch {
+    return;
+  }
+  let res;
+  try {
+    res = { id: req.id, ...compile(req.project) };
+  } catch (err) {
+    res = { id: req.id, error: String((err && err.stack) || err) };
+  }
+  process.stdout.write(JSON.stringify(res) + "\n");
+});
+rl.on("close", () => process.exit(0));
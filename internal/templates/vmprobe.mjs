@@ -0,0 +1,37 @@
+// Checks that this runtime's node:vm does what runner.mjs relies on to keep
+// koan code in its sandbox. Prints one line for each thing it doesn't do,
+// then "done". The endless loop goes last: if vm can't stop it, "done" never
+// comes and ts-koans gives up waiting.
+import vm from "node:vm";
+
+function check(problem, ok) {
+  try {
+    if (ok()) return;
+  } catch {}
+  console.log(problem);
+}
+
+// throws reports whether running code in context throws.
+function throws(code, context, options) {
+  try {
+    vm.runInContext(code, context, options);
+    return false;
+  } catch {
+    return true;
+  }
+}
+
+let context;
+check("a context can't refuse eval() and new Function()", () => {
+  context = vm.createContext({}, { codeGeneration: { strings: false, wasm: false } });
+  return true;
+});
+if (context) {
+  check("eval() isn't refused in the context", () => throws(`eval("1")`, context));
+  check("new Function() isn't refused in the context", () => throws(`new Function("return 1")()`, context));
+  check("the context can see the host's globals", () => vm.runInContext("typeof process", context) === "undefined");
+  check("vm.compileFunction can't compile into the context", () =>
+    typeof vm.compileFunction("return 1", [], { parsingContext: context }) === "function");
+  check("an endless loop isn't stopped by vm's timeout", () => throws("while (true) {}", context, { timeout: 100 }));
+}
+console.log("done");
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Live type-checking ---
+//
+// With live checking on ([ctrl+l]), the code is type-checked in the
+// background shortly after the learner stops typing, and the diagnostics
+// replace the output panel. Tests only run on F5. Every edit bumps
+// m.editSeq; a pending check or a result that carries an older number is
+// dropped, so a slow check can never overwrite a newer one. Checks still
+// in flight are cancelled when a full run starts or the koan changes, so
+// neither waits behind them for the compiler.
+
+const liveCheckDelay = 600 * time.Millisecond
+
+// liveCheckTickMsg fires liveCheckDelay after an edit.
+type liveCheckTickMsg struct{ seq int }
+
+// liveCheckDoneMsg carries the result of a background type-check.
+type liveCheckDoneMsg struct {
+	seq   int
+	lines []runnerOutputMsg
+	marks []editorMark
+}
+
+// edited records a change to the code and, with live checking on, schedules
+// a check once the learner pauses.
+func (m *model) edited() tea.Cmd {
+	m.editSeq++
+	if !m.liveCheck {
+		return nil
+	}
+	seq := m.editSeq
+	return tea.Tick(liveCheckDelay, func(time.Time) tea.Msg { return liveCheckTickMsg{seq: seq} })
+}
+
+// startLiveCheck type-checks the current code in the background.
+func (m *model) startLiveCheck() tea.Cmd {
+	ex := m.exercises[m.selected]
+	userCode := m.mainCode()
+	files := projectFiles(ex, m.fileEdits())
+	seq := m.editSeq
+	if m.liveCtx == nil {
+		m.liveCtx, m.cancelLive = context.WithCancel(context.Background())
+	}
+	ctx := m.liveCtx
+	return func() tea.Msg {
+		var out outputCollector
+		if blanks := describeAllBlanks(userCode, files); blanks != "" {
+			out.Send(runnerOutputMsg{Line: fmt.Sprintf("✋ %s.", blanks)})
+		} else if typeCheckExercise(ctx, userCode, files, ex, &out) == nil {
+			out.Send(runnerOutputMsg{Line: "✔ Type-checks. Press [F5] to run the tests."})
+		}
+		return liveCheckDoneMsg{seq: seq, lines: out.msgs, marks: out.marks}
+	}
+}
+
+// typeCheckExercise is runExercise without emitting JavaScript or running
+// the tests.
+func typeCheckExercise(ctx context.Context, userCode string, files []internal.SourceFile, ex internal.Exercise, program msgSender) error {
+	tmpDir, err := os.MkdirTemp("", "tskoans-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	return compileTypeScript(ctx, tmpDir, userCode, files, withNoEmit(ex), program)
+}
+
+// stopLiveChecks cancels the live checks in flight.
+func (m *model) stopLiveChecks() {
+	if m.cancelLive != nil {
+		m.cancelLive()
+		m.liveCtx, m.cancelLive = nil, nil
+	}
+}
+
+// updateLiveCheck handles the live-check messages.
+func (m model) updateLiveCheck(msg tea.Msg) (model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case liveCheckTickMsg:
+		if !m.liveCheck || msg.seq != m.editSeq {
+			return m, nil
+		}
+		cmd := m.startLiveCheck()
+		return m, cmd
+	case liveCheckDoneMsg:
+		// A full run owns the output panel until it is done.
+		if !m.liveCheck || msg.seq != m.editSeq || m.running {
+			return m, nil
+		}
+		m.outputLines = msg.lines
+		m.marks = msg.marks
+		m.recalcEditorHeight()
+	}
+	return m, nil
+}
+
+// toggleLiveCheck turns live checking on or off. Turning it on checks the
+// code right away.
+func (m *model) toggleLiveCheck() tea.Cmd {
+	m.liveCheck = !m.liveCheck
+	m.recalcEditorHeight()
+	if !m.liveCheck {
+		m.stopLiveChecks()
+		return nil
+	}
+	m.editSeq++
+	return m.startLiveCheck()
+}
@@ -1,16 +1,15 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -47,6 +46,7 @@ const (
 
 	listItemHeight = 3 // Default delegate Height(2) + Spacing(1)
 
+	infoChrome     = 6 // infoStyle MarginLeft(2) + Border(1) + PaddingLeft(1) + right border(1) + safety(1)
 	tabWidth       = 2
 	maxBufferLines = 100 // Max retained output/debug lines
 )
@@ -71,6 +71,22 @@ type model struct {
 	editorTopY      int
 	editorHeight    int
 	outputHeight    int
+	collapsed       map[string]bool    // chapter name -> collapsed in the menu
+	packErrs        []error            // invalid koan pack entries, listed under the menu
+	stayInChapter   bool               // shift+←/→ only moves within the current chapter
+	buffers         []string           // code of each of the exercise's files, main file first
+	activeFile      int                // index into buffers of the file in the editor
+	liveCheck       bool               // type-check in the background while typing
+	liveCtx         context.Context    // live checks run under it; see live.go
+	cancelLive      context.CancelFunc // stops the live checks in flight
+	marks           []editorMark       // errors from the last compile, drawn in the editor
+	editSeq         int                // bumped on every edit; see live.go
+	currentTest     string             // test case the runner is on
+	consoleLines    []string           // what the koan's code logged in the last run; see console.go
+	outputTab       outputTab          // tab showing in the output panel
+	outputScroll    int                // lines the output panel is scrolled up by
+	cancelRun       context.CancelFunc // stops the run in flight
+	runID           int                // ID of the latest run; see runs.go
 }
 
 type setProgramMsg struct{ program *tea.Program }
@@ -80,6 +96,9 @@ type runnerOutputMsg struct {
 	Line      string
 	Assertion bool
 }
+
+// runnerDoneMsg ends a run. Err is nil only if the runner's summary said
+// every test passed.
 type runnerDoneMsg struct{ Err error }
 
 var (
@@ -126,26 +145,6 @@ var (
 	assertionStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Bold(true)
 )
 
-func printHelpfulTSCErrors(tscOutput, harnessPath string, p *tea.Program) {
-	harnessBytes, _ := os.ReadFile(harnessPath)
-	harnessLines := strings.Split(string(harnessBytes), "\n")
-	scanner := bufio.NewScanner(strings.NewReader(tscOutput))
-	// errorLinePattern matches e.g. "typecheck.ts(10,44): error ..."
-	errorLinePattern := regexp.MustCompile(`typecheck\.ts\((\d+),\d+\): error`)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if m := errorLinePattern.FindStringSubmatch(line); m != nil {
-			lineNum, _ := strconv.Atoi(m[1])
-			// Print the comment line (if any) above the assertion
-			if lineNum-2 >= 0 && lineNum-2 < len(harnessLines) {
-				p.Send(runnerOutputMsg{Line: harnessLines[lineNum-2], Assertion: true})
-			}
-		}
-		// Always print the error itself
-		p.Send(runnerOutputMsg{Line: line})
-	}
-}
-
 func (m *model) appendDebug(msg string) {
 	if m.debugMode {
 		m.debugLog = append(m.debugLog, msg)
@@ -168,12 +167,13 @@ func (m *model) recalcEditorHeight() {
 		debugPanelHeight = lipgloss.Height(debugStyle.Width(m.width - panelHorizChrome).Render(strings.Repeat("\n", debugPanelLines-1)))
 	}
 
-	help := helpStyle.Render("[esc] Back | [F5] Run | [shift + ← / → ] Prev/Next Exercise")
+	help := helpStyle.Width(m.width - panelHorizChrome).Render(m.editorHelp())
 
 	fixedHeight := lipgloss.Height(header) +
 		lipgloss.Height(desc) +
 		debugPanelHeight +
-		lipgloss.Height(help)
+		lipgloss.Height(help) +
+		statusLineHeight
 
 	available := m.height - fixedHeight - panelVertChrome
 
@@ -189,32 +189,18 @@ func (m *model) recalcEditorHeight() {
 
 	m.textarea.SetWidth(m.width - panelHorizChrome)
 	m.textarea.SetHeight(m.editorHeight)
-}
-
-func makeListItems(exs []internal.Exercise, completed map[int]bool) []list.Item {
-	items := make([]list.Item, len(exs))
-	for i, ex := range exs {
-		label := ex.Title()
-		if completed[i] {
-			label = "✅ " + label
-		}
-		ex2 := ex
-		ex2.Label = label
-		items[i] = ex2
+	if m.hasTabs() {
+		// The tab bar takes the editor's first row.
+		m.editorTopY++
+		m.textarea.SetHeight(m.editorHeight - 1)
 	}
-	return items
 }
 
-func initialModel(state internal.PersistentState) model {
-	exs := internal.Exercises()
-	items := make([]list.Item, len(exs))
-	for i, ex := range exs {
-		items[i] = ex
-	}
-
-	l := list.New(makeListItems(exs, state.Completed), list.NewDefaultDelegate(), 30, 14)
+func initialModel(state internal.PersistentState, exs []internal.Exercise) model {
+	l := list.New(makeListItems(exs, state, nil), newMenuDelegate(), 30, 14)
 	l.Title = "Select an Exercise"
 	l.SetShowHelp(false)
+	l.Filter = filterMenu
 
 	t := textarea.New()
 	t.Placeholder = "Edit your code here..."
@@ -225,9 +211,17 @@ func initialModel(state internal.PersistentState) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 
+	selected := 0
+	for i, ex := range exs {
+		if ex.ID == state.SelectedID {
+			selected = i
+			break
+		}
+	}
+
 	m := model{
 		persistentState: state,
-		selected:        state.SelectedIndex,
+		selected:        selected,
 		state:           menu,
 		list:            l,
 		textarea:        t,
@@ -236,11 +230,7 @@ func initialModel(state internal.PersistentState) model {
 	}
 
 	// If user has a saved solution for this exercise, load it into textarea
-	if code, ok := state.Solutions[state.SelectedIndex]; ok && code != "" {
-		m.textarea.SetValue(code)
-	} else {
-		m.textarea.SetValue(m.exercises[m.selected].StarterCode)
-	}
+	m.loadBuffers()
 	return m
 }
 
@@ -261,74 +251,267 @@ func copyVersionFilesToTempDir(tempDir string) {
 	}
 }
 
-func runExerciseStreamed(userCode string, program *tea.Program, ex internal.Exercise) tea.Cmd {
+// msgSender receives runner messages: the *tea.Program in the TUI, or a
+// collector when running headless (see validate.go).
+type msgSender interface {
+	Send(msg tea.Msg)
+}
+
+// Runs are stopped if they take too long, and the learner can cancel them.
+// tscTimeout can be changed with -tsc-timeout.
+var tscTimeout = 30 * time.Second
+
+// nodeTimeout leaves node time to start on top of runner.mjs's
+// totalTimeout, so the runner always reports on every case before it is
+// killed.
+const nodeTimeout = 5 * time.Second
+
+func runExerciseStreamed(ctx context.Context, cancel context.CancelFunc, program runSender, userCode string, files []internal.SourceFile, ex internal.Exercise) tea.Cmd {
 	return func() tea.Msg {
-		tmpDir, err := os.MkdirTemp("", "tskoans-*")
-		if err != nil {
-			program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to create temp dir: %v", err)})
-			program.Send(runnerDoneMsg{Err: err})
+		defer cancel()
+		if blanks := describeAllBlanks(userCode, files); blanks != "" {
+			program.Send(runnerOutputMsg{Line: fmt.Sprintf("✋ %s. Fill them in first ([F3] jumps to the next one).", blanks)})
+			program.Send(runnerDoneMsg{Err: errors.New("unfilled blanks")})
 			return nil
 		}
-		copyVersionFilesToTempDir(tmpDir)
-		defer os.RemoveAll(tmpDir)
-
-		if err := compileTypeScript(tmpDir, userCode, ex, program); err != nil {
-			program.Send(runnerDoneMsg{Err: err})
-			return nil
+		err := runExercise(ctx, userCode, files, ex, program)
+		if errors.Is(err, context.Canceled) {
+			program.Send(runnerOutputMsg{Line: "⏹ Run cancelled."})
 		}
+		program.Send(runnerDoneMsg{Err: err})
+		return nil
+	}
+}
 
-		if err := writeTestBundle(tmpDir, ex.TestScript); err != nil {
-			program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to write test bundle: %v", err)})
-			program.Send(runnerDoneMsg{Err: err})
-			return nil
+// runExercise type-checks userCode, along with the exercise's other files,
+// against ex and runs its tests, sending output as it goes. Returns nil only
+// if everything passed, and ctx.Err() if ctx is cancelled.
+//
+// Unless -no-cache is given, a run whose inputs haven't changed since an
+// earlier one replays its results instead (see cache.go).
+func runExercise(ctx context.Context, userCode string, files []internal.SourceFile, ex internal.Exercise, program msgSender) error {
+	if !runCacheEnabled {
+		return compileAndRun(ctx, userCode, files, ex, program)
+	}
+	key := runCacheKey(ctx, userCode, files, ex)
+	if replayed, err := replayCachedRun(key, program); replayed {
+		return err
+	}
+	rec := &runRecorder{program: program}
+	err := compileAndRun(ctx, userCode, files, ex, rec)
+	if cacheable(err) {
+		if saveErr := rec.save(key, err == nil); saveErr != nil {
+			program.Send(runnerDebugMsg{Line: "cache: " + saveErr.Error()})
 		}
+	}
+	return err
+}
 
-		err = runNodeTests(tmpDir, program)
-		program.Send(runnerDoneMsg{Err: err})
+// compileAndRun is runExercise without the cache.
+func compileAndRun(ctx context.Context, userCode string, files []internal.SourceFile, ex internal.Exercise, program msgSender) error {
+	tmpDir, err := os.MkdirTemp("", "tskoans-*")
+	if err != nil {
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to create temp dir: %v", err)})
+		return err
+	}
+	copyVersionFilesToTempDir(tmpDir)
+	defer os.RemoveAll(tmpDir)
+
+	if ex.TypeOnly {
+		// Nothing runs, so there is nothing to emit.
+		if err := compileTypeScript(ctx, tmpDir, userCode, files, withNoEmit(ex), program); err != nil {
+			return err
+		}
+		program.Send(runnerOutputMsg{Line: "✅ All type checks passed!"})
 		return nil
 	}
+	if ex.ESM && !runsESM(backendFor(ctx)) {
+		program.Send(runnerOutputMsg{Line: "⚠ " + errESMUnsupported.Error() + ". Start ts-koans with -backend tsc to solve this one."})
+		return errESMUnsupported
+	}
+	if err := compileTypeScript(ctx, tmpDir, userCode, files, ex, program); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if nodeMissing {
+		program.Send(runnerOutputMsg{Line: "⚠ The code type-checks, but this koan's tests need Node.js, which isn't in PATH."})
+		return errNodeMissing
+	}
+
+	if err := writeTestBundle(tmpDir, ex); err != nil {
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to write test bundle: %v", err)})
+		return err
+	}
+
+	return runNodeTests(ctx, tmpDir, program)
+}
+
+// defaultCompilerOptions are used for every exercise unless it overrides
+// them in Exercise.CompilerOptions.
+var defaultCompilerOptions = map[string]any{
+	"target": "es2020",
+	"module": "commonjs",
+	"outDir": ".",
+	"pretty": false,
+}
+
+// esmCompilerOptions replace some of the defaults for ES module exercises.
+// Every file is a module, so top-level await works even without imports.
+var esmCompilerOptions = map[string]any{
+	"target":          "es2022",
+	"module":          "es2022",
+	"moduleDetection": "force",
+}
+
+// compilerOptionsFor merges an exercise's compiler options over the defaults.
+func compilerOptionsFor(ex internal.Exercise) map[string]any {
+	opts := make(map[string]any, len(defaultCompilerOptions)+len(ex.CompilerOptions))
+	for k, v := range defaultCompilerOptions {
+		opts[k] = v
+	}
+	if ex.ESM {
+		for k, v := range esmCompilerOptions {
+			opts[k] = v
+		}
+	}
+	for k, v := range ex.CompilerOptions {
+		opts[k] = v
+	}
+	return opts
+}
+
+// withNoEmit returns ex set up to be type-checked without writing any
+// JavaScript.
+func withNoEmit(ex internal.Exercise) internal.Exercise {
+	opts := map[string]any{"noEmit": true}
+	for k, v := range ex.CompilerOptions {
+		opts[k] = v
+	}
+	ex.CompilerOptions = opts
+	return ex
+}
+
+// writeTSConfig writes a tsconfig.json for the exercise into tmpDir and
+// returns its path along with the effective compiler options as JSON.
+func writeTSConfig(tmpDir string, ex internal.Exercise) (string, string, error) {
+	opts, err := json.Marshal(compilerOptionsFor(ex))
+	if err != nil {
+		return "", "", fmt.Errorf("encode compiler options: %w", err)
+	}
+	names := []string{"typecheck.ts"}
+	for _, f := range ex.Files {
+		names = append(names, f.Name)
+	}
+	files, _ := json.Marshal(names)
+	tsconfig := fmt.Sprintf(`{"compilerOptions": %s, "files": %s}`, opts, files)
+	path := filepath.Join(tmpDir, "tsconfig.json")
+	if err := os.WriteFile(path, []byte(tsconfig), 0644); err != nil {
+		return "", "", fmt.Errorf("write tsconfig.json: %w", err)
+	}
+	return path, string(opts), nil
 }
 
 // compileTypeScript writes the user code + type harness + assertions to a .ts file,
-// then runs tsc. Returns nil on success, or the tsc error (after sending output messages).
-func compileTypeScript(tmpDir, userCode string, ex internal.Exercise, program *tea.Program) error {
+// and any other files of the exercise next to it, then runs tsc. Returns nil on
+// success, or the tsc error (after sending output messages).
+func compileTypeScript(ctx context.Context, tmpDir, userCode string, files []internal.SourceFile, ex internal.Exercise, program msgSender) error {
 	typecheckPath := filepath.Join(tmpDir, "typecheck.ts")
-	fullTypecheck := userCode + "\n\n" + internal.TypeHarness + "\n" + ex.TypeAssertions + "\n"
+	fullTypecheck, layout := buildTypecheck(userCode, ex)
 
 	if err := os.WriteFile(typecheckPath, []byte(fullTypecheck), 0644); err != nil {
 		program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to write typecheck.ts: %v", err)})
 		return err
 	}
+	if err := writeSourceFiles(tmpDir, files); err != nil {
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("Failed to write source files: %v", err)})
+		return err
+	}
 
-	tscCmd := tscCommand(typecheckPath, tmpDir)
-	tscCmd.Dir = tmpDir
+	tsconfigPath, opts, err := writeTSConfig(tmpDir, ex)
+	if err != nil {
+		program.Send(runnerOutputMsg{Line: err.Error()})
+		return err
+	}
+	program.Send(runnerDebugMsg{Line: "compilerOptions: " + opts})
 
-	var tscStderrBuf, tscStdoutBuf bytes.Buffer
-	tscCmd.Stderr = &tscStderrBuf
-	tscCmd.Stdout = &tscStdoutBuf
+	var expects []tsExpectation
+	if ex.Kind == internal.KindExpectError {
+		expects = parseExpectations(userCode)
+	}
 
-	if err := tscCmd.Run(); err != nil {
+	stdout, stderr, err := runTSC(ctx, tsconfigPath, tmpDir)
+	if tscStopped(err, program) {
+		return err
+	}
+	if err != nil {
 		program.Send(runnerDebugMsg{Line: fmt.Sprintf("tsc exit error: %v", err)})
-		program.Send(runnerDebugMsg{Line: "STDERR: " + tscStderrBuf.String()})
-		program.Send(runnerDebugMsg{Line: "STDOUT: " + tscStdoutBuf.String()})
-		printHelpfulTSCErrors(tscStdoutBuf.String(), typecheckPath, program)
+		program.Send(runnerDebugMsg{Line: "STDERR: " + stderr})
+		program.Send(runnerDebugMsg{Line: "STDOUT: " + stdout})
+		diags := parseDiagnostics(stdout)
+		newDiagnosticReport(fullTypecheck, layout, files, expects).send(diags, program)
 		program.Send(runnerOutputMsg{Line: fmt.Sprintf("[tsc] Compilation failed: %v", err)})
+		for _, d := range diags {
+			if d.Code != 0 {
+				return fmt.Errorf("%w: %v", errTypeCheckFailed, err)
+			}
+		}
 		return err
 	}
+
+	if ex.Kind == internal.KindExpectError {
+		if err := checkExpectations(ctx, tmpDir, userCode, files, ex, program); err != nil {
+			program.Send(runnerOutputMsg{Line: "[tsc] The code compiled, but not in the way this koan expects."})
+			return err
+		}
+	}
 	return nil
 }
 
+// runTSC type-checks, and unless told not to compiles, a tsconfig.json with
+// ctx's backend from dir, and returns the output. It gives up after
+// tscTimeout, or when ctx is cancelled, and returns the context's error.
+func runTSC(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, tscTimeout)
+	defer cancel()
+
+	stdout, stderr, err := backendFor(ctx).Compile(ctx, tsconfigPath, dir)
+	if ctx.Err() != nil {
+		return "", "", ctx.Err()
+	}
+	return stdout, stderr, err
+}
+
+// tscStopped reports whether err means runTSC gave up before tsc finished,
+// and says so if it ran out of time.
+func tscStopped(err error, program msgSender) bool {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("⏱ Type-checking timed out after %s. Start ts-koans with -tsc-timeout to allow it longer.", tscTimeout)})
+		return true
+	case errors.Is(err, context.Canceled):
+		return true
+	}
+	return false
+}
+
 // writeTestBundle reads the compiled JS, combines it with the test script,
-// and writes both run.js and runner.mjs into tmpDir.
-func writeTestBundle(tmpDir, testScript string) error {
+// and writes both run.js (run.mjs for an ES module exercise) and runner.mjs
+// into tmpDir.
+func writeTestBundle(tmpDir string, ex internal.Exercise) error {
 	jsBytes, err := os.ReadFile(filepath.Join(tmpDir, "typecheck.js"))
 	if err != nil {
 		return fmt.Errorf("read compiled JS: %w", err)
 	}
 
-	combined := fmt.Sprintf("%s\n\n%s\n", string(jsBytes), testScript)
-	if err := os.WriteFile(filepath.Join(tmpDir, "run.js"), []byte(combined), 0644); err != nil {
-		return fmt.Errorf("write run.js: %w", err)
+	bundle := "run.js"
+	if ex.ESM {
+		bundle = "run.mjs"
+	}
+	combined := fmt.Sprintf("%s\n\n%s\n", string(jsBytes), ex.TestScript)
+	if err := os.WriteFile(filepath.Join(tmpDir, bundle), []byte(combined), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", bundle, err)
 	}
 
 	if err := os.WriteFile(filepath.Join(tmpDir, "runner.mjs"), []byte(internal.RunnerMJS), 0644); err != nil {
@@ -337,45 +520,95 @@ func writeTestBundle(tmpDir, testScript string) error {
 	return nil
 }
 
-// runNodeTests executes runner.mjs with a timeout and sends stdout/stderr as output messages.
-func runNodeTests(tmpDir string, program *tea.Program) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+var (
+	// errTypeCheckFailed means the compiler judged the code, and rejected
+	// it or didn't reject it the way an expect-error koan asks.
+	errTypeCheckFailed = errors.New("type check failed")
+	// errTestsFailed means the tests ran, and not all of them passed.
+	errTestsFailed = errors.New("tests failed")
+	// errTestsUnsettled means the tests failed in a way that might not
+	// repeat: a case timed out, or the sandbox stopped the runner.
+	errTestsUnsettled = errors.New("tests failed, and might not next time")
+)
+
+// runNodeTests executes runner.mjs with the backend's runtime and a
+// timeout. Its results arrive as events (see events.go) and are sent on as
+// they come; anything it prints to stdout or stderr is sent at the end. It
+// returns nil only if the runner's summary says every test passed.
+func runNodeTests(ctx context.Context, tmpDir string, program msgSender) error {
+	ctx, cancel := context.WithTimeout(ctx, nodeTimeout)
 	defer cancel()
 
-	nodeCmd := exec.CommandContext(ctx, "node", filepath.Join(tmpDir, "runner.mjs"))
+	b := backendFor(ctx)
+	nodeCmd := b.TestCommand(ctx, tmpDir, filepath.Join(tmpDir, "runner.mjs"))
 	nodeCmd.Dir = tmpDir
+	killTreeOnCancel(nodeCmd)
 
 	var nodeStdoutBuf, nodeStderrBuf bytes.Buffer
 	nodeCmd.Stdout = &nodeStdoutBuf
 	nodeCmd.Stderr = &nodeStderrBuf
 
-	err := nodeCmd.Run()
-
-	if ctx.Err() == context.DeadlineExceeded {
-		program.Send(runnerOutputMsg{Line: "[node] Execution timed out!"})
+	events, started, exited, err := b.EventPipe(nodeCmd)
+	if err != nil {
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("[node] Test runner failed: %v", err)})
+		return err
+	}
+	defer events.Close()
+	err = nodeCmd.Start()
+	started()
+	if err != nil {
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("[node] Test runner failed: %v", err)})
+		return err
+	}
+	summaryCh := make(chan *testSummaryMsg, 1)
+	go func() { summaryCh <- readRunnerEvents(events, program) }()
+	err = nodeCmd.Wait()
+	exited()
+	summary := <-summaryCh
+
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("⏱ The tests timed out after %s. Is there an endless loop?", nodeTimeout)})
+		return ctx.Err()
+	case context.Canceled:
 		return ctx.Err()
 	}
 	if nodeStdoutBuf.Len() > 0 {
 		program.Send(runnerOutputMsg{Line: "[node stdout] " + nodeStdoutBuf.String()})
 	}
-	if nodeStderrBuf.Len() > 0 {
+	violation := sandboxViolation(nodeStderrBuf.String())
+	if violation != "" {
+		program.Send(runnerOutputMsg{Line: violation})
+		program.Send(runnerDebugMsg{Line: "[node stderr] " + nodeStderrBuf.String()})
+	} else if nodeStderrBuf.Len() > 0 {
 		program.Send(runnerOutputMsg{Line: "[node stderr] " + nodeStderrBuf.String()})
 	}
-	if err != nil {
-		program.Send(runnerOutputMsg{Line: fmt.Sprintf("[node] Test runner failed: %v", err)})
-	}
-	return err
-}
-
-func outputLinesToString(lines []runnerOutputMsg) string {
-	strs := make([]string, len(lines))
-	for i, msg := range lines {
-		strs[i] = msg.Line
+	switch {
+	case summary == nil:
+		if err == nil {
+			err = errors.New("no result")
+		}
+		if violation == "" {
+			program.Send(runnerOutputMsg{Line: fmt.Sprintf("[node] Test runner failed: %v", err)})
+		}
+		return err
+	case !summary.OK() && (summary.TimedOut || violation != ""):
+		return errTestsUnsettled
+	case !summary.OK():
+		return errTestsFailed
+	case err != nil:
+		// The runner only exits non-zero after a passing summary if
+		// something went wrong after it.
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("[node] Test runner failed after the tests passed: %v", err)})
+		return err
 	}
-	return strings.Join(strs, "\n")
+	return nil
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(runMsg); ok {
+		return m.updateRun(msg)
+	}
 	switch m.state {
 	case menu:
 		return m.updateMenu(msg)
@@ -405,11 +638,16 @@ func (m model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case setProgramMsg:
 		m.program = msg.program
 
+	case runnerDebugMsg:
+		m.appendDebug(msg.Line)
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		// Reserve 2 lines for the help text rendered below the list
-		m.list.SetSize(msg.Width, msg.Height-2)
+		// Reserve 2 lines for the help text rendered below the list, and
+		// room for the pack errors
+		m.list.SetSize(msg.Width, msg.Height-2-lipgloss.Height(m.packErrorsBanner()))
 
 	case tea.MouseMsg:
 		// Don't intercept clicks while the filter input is focused
@@ -435,26 +673,41 @@ func (m model) updateMenu(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.saveState()
 				return m, tea.Quit
 			case "enter":
-				m.outputLines = nil
-				m.switchToExercise(m.list.GlobalIndex())
-				m.textarea.Focus()
-				m.state = editor
+				switch item := m.list.SelectedItem().(type) {
+				case chapterItem:
+					m.toggleChapter(item.name)
+					return m, nil
+				case exerciseItem:
+					m.outputLines = nil
+					m.switchToExercise(item.index)
+					m.textarea.Focus()
+					m.state = editor
+				}
 			}
 		}
 	}
+	wasFiltering := m.list.FilterState() != list.Unfiltered
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	if filtering := m.list.FilterState() != list.Unfiltered; filtering != wasFiltering {
+		m.setMenuItems()
+	}
 	return m, cmd
 }
 
 func (m model) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case runnerOutputMsg:
-		m.outputLines = append(m.outputLines, msg)
-		if len(m.outputLines) > maxBufferLines {
-			m.outputLines = m.outputLines[len(m.outputLines)-maxBufferLines:]
+		m.appendOutput(msg)
+
+	case runnerEventMsg:
+		switch msg := msg.(type) {
+		case testStartMsg:
+			m.currentTest = msg.Name
+		case consoleMsg:
+			m.appendConsole(msg)
 		}
-		m.recalcEditorHeight()
+		m.appendOutput(msg.outputLines()...)
 
 	case runnerDebugMsg:
 		if m.debugMode {
@@ -463,19 +716,18 @@ func (m model) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, nil
 
-	case runnerDoneMsg:
+	case liveCheckTickMsg, liveCheckDoneMsg:
+		return m.updateLiveCheck(msg)
+
+	case diagnosticsMsg:
+		m.marks = msg.Marks
+		return m, nil
+
+	case runnerDoneMsg, matrixDoneMsg:
 		m.running = false
+		m.currentTest = ""
+		m.cancelRun = nil
 		m.recalcEditorHeight()
-
-		output := outputLinesToString(m.outputLines)
-		if strings.Contains(output, "✅") {
-			if m.persistentState.Completed == nil {
-				m.persistentState.Completed = make(map[int]bool)
-			}
-			m.persistentState.Completed[m.selected] = true
-			m.list.SetItems(makeListItems(m.exercises, m.persistentState.Completed))
-			m.saveState()
-		}
 		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -502,28 +754,7 @@ func (m model) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 				targetCol = 0
 			}
 
-			// Move cursor in textarea
-			currentLine := m.textarea.Line()
-			for currentLine < targetLine {
-				m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyDown})
-				currentLine++
-			}
-			for currentLine > targetLine {
-				m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyUp})
-				currentLine--
-			}
-
-			// Clamp col to len of target line, set col
-			lines := strings.Split(m.textarea.Value(), "\n")
-			lineLen := len([]rune(lines[targetLine]))
-			if targetCol > lineLen {
-				targetCol = lineLen
-			}
-
-			m.textarea.SetCursor(targetCol)
-
-			m.calculateCursorCoordinates()
-
+			m.moveCursor(targetLine, targetCol)
 			return m, nil
 		}
 	case tea.KeyMsg:
@@ -533,27 +764,91 @@ func (m model) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.saveState()
 			return m, tea.Quit
 		case "esc":
+			m.supersedeRun()
 			m.outputLines = nil
 			m.saveState()
 			m.state = menu
 			m.textarea.Blur()
 			return m, nil
 		case "tab":
+			if m.activeReadOnly() {
+				return m, nil
+			}
 			m.textarea = insertSpacesAtCursor(m.textarea, tabWidth)
-			return m, nil
+			return m, m.edited()
 		case "f5":
+			if m.running {
+				return m, nil
+			}
 			m.saveState()
-			userCode := m.textarea.Value()
+			ex := m.exercises[m.selected]
+			files := projectFiles(ex, m.fileEdits())
+			m.outputLines = nil
+			m.consoleLines = nil
+			m.outputScroll = 0
+			m.marks = nil
+			m.running = true
+			m.stopLiveChecks()
+			m.editSeq++ // a pending live check must not replace the results
+			m.recalcEditorHeight()
+			ctx, cancel := context.WithCancel(context.Background())
+			m.cancelRun = cancel
+			m.runID++
+			run := runSender{program: m.program, run: m.runID, exerciseID: ex.ID}
+			return m, tea.Batch(m.spinner.Tick, runExerciseStreamed(ctx, cancel, run, m.mainCode(), files, ex))
+		case "f8":
+			if m.running {
+				return m, nil
+			}
+			ex := m.exercises[m.selected]
 			m.outputLines = nil
+			m.outputTab = testsTab
+			m.outputScroll = 0
 			m.running = true
+			m.stopLiveChecks()
+			m.editSeq++
 			m.recalcEditorHeight()
-			return m, tea.Batch(m.spinner.Tick, runExerciseStreamed(userCode, m.program, m.exercises[m.selected]))
+			ctx, cancel := context.WithCancel(context.Background())
+			m.cancelRun = cancel
+			m.runID++
+			run := runSender{program: m.program, run: m.runID, exerciseID: ex.ID}
+			return m, tea.Batch(m.spinner.Tick, runVersionMatrix(ctx, cancel, run, m.mainCode(), projectFiles(ex, m.fileEdits()), ex))
+		case "ctrl+x":
+			if m.running && m.cancelRun != nil {
+				m.cancelRun()
+			}
+			return m, nil
+		case "f4":
+			m.switchFile(1)
+			return m, nil
+		case "f7":
+			m.switchOutputTab()
+			return m, nil
+		case "pgup":
+			m.scrollOutput(1)
+			return m, nil
+		case "pgdown":
+			m.scrollOutput(-1)
+			return m, nil
 		case "shift+right":
-			m.switchToExercise((m.selected + 1) % len(m.exercises))
+			m.switchToExercise(m.neighbourExercise(1))
 			return m, nil
 		case "shift+left":
-			m.switchToExercise((m.selected - 1 + len(m.exercises)) % len(m.exercises))
+			m.switchToExercise(m.neighbourExercise(-1))
+			return m, nil
+		case "f2":
+			m.revealHint()
 			return m, nil
+		case "f3":
+			m.jumpToNextBlank()
+			return m, nil
+		case "ctrl+g":
+			m.stayInChapter = !m.stayInChapter
+			m.recalcEditorHeight()
+			return m, nil
+		case "ctrl+l":
+			cmd := m.toggleLiveCheck()
+			return m, cmd
 		}
 	}
 	if m.running {
@@ -561,30 +856,136 @@ func (m model) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, spinCmd = m.spinner.Update(msg)
 		return m, spinCmd
 	}
+	if m.activeReadOnly() {
+		// Let the textarea move the cursor, but undo any edit.
+		before := m.textarea.Value()
+		line, col := m.textarea.Line(), m.textarea.LineInfo().StartColumn+m.textarea.LineInfo().ColumnOffset
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		if m.textarea.Value() != before {
+			m.textarea.SetValue(before)
+			m.moveCursor(line, col)
+		}
+		m.calculateCursorCoordinates()
+		return m, cmd
+	}
+	before := m.textarea.Value()
 	var cmd tea.Cmd
 	m.textarea, cmd = m.textarea.Update(msg)
 	// Calculate cursor start
 	m.calculateCursorCoordinates()
+	if m.textarea.Value() != before {
+		cmd = tea.Batch(cmd, m.edited())
+	}
 	return m, cmd
 }
 
 func (m *model) saveState() {
-	m.persistentState.SelectedIndex = m.selected
-	m.persistentState.Solutions[m.selected] = m.textarea.Value()
+	id := m.exercises[m.selected].ID
+	m.persistentState.SelectedID = id
+	m.persistentState.Solutions[id] = m.mainCode()
+	if edits := m.fileEdits(); len(edits) > 0 {
+		if m.persistentState.FileSolutions == nil {
+			m.persistentState.FileSolutions = make(map[string]map[string]string)
+		}
+		m.persistentState.FileSolutions[id] = edits
+	}
 	internal.SaveState(m.persistentState)
 }
 
+// revealHint shows the next hint for the current exercise and remembers
+// how many have been used. When the info panel is hidden, the hint goes to
+// the output panel instead.
+func (m *model) revealHint() {
+	ex := m.exercises[m.selected]
+	used := m.persistentState.HintsUsed[ex.ID]
+	switch {
+	case len(ex.Hints) == 0:
+		m.outputLines = append(m.outputLines, runnerOutputMsg{Line: "There are no hints for this koan."})
+		return
+	case used >= len(ex.Hints):
+		m.outputLines = append(m.outputLines, runnerOutputMsg{Line: "No more hints. Sit with it a while."})
+		return
+	}
+	if m.persistentState.HintsUsed == nil {
+		m.persistentState.HintsUsed = make(map[string]int)
+	}
+	m.persistentState.HintsUsed[ex.ID] = used + 1
+	m.saveState()
+	if !m.infoPanelVisible() {
+		m.outputLines = append(m.outputLines, runnerOutputMsg{Line: hintLine(ex, used)})
+	}
+}
+
+func hintLine(ex internal.Exercise, i int) string {
+	return fmt.Sprintf("💡 Hint %d/%d: %s", i+1, len(ex.Hints), ex.Hint(i))
+}
+
+// infoText is the info panel content: the exercise's info followed by any
+// hints revealed so far.
+func (m model) infoText() string {
+	ex := m.exercises[m.selected]
+	parts := []string{}
+	if ex.Info() != "" {
+		parts = append(parts, dedent.Dedent(ex.Info()))
+	}
+	for i := 0; i < m.persistentState.HintsUsed[ex.ID] && i < len(ex.Hints); i++ {
+		parts = append(parts, hintLine(ex, i))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// infoPanelVisible reports whether the terminal is wide enough for the info
+// panel next to the editor.
+func (m model) infoPanelVisible() bool {
+	return m.width > 80 && m.infoText() != "" && m.infoPanelWidth() > 10
+}
+
+func (m model) infoPanelWidth() int {
+	return m.width - panelHorizChrome - m.editorPanelWidth() - infoChrome
+}
+
+func (m model) editorPanelWidth() int {
+	return (m.width - panelHorizChrome) * 60 / 100
+}
+
 func (m *model) switchToExercise(i int) {
 	m.saveState()
 	m.selected = i
-	if code, ok := m.persistentState.Solutions[i]; ok && code != "" {
-		m.textarea.SetValue(code)
-	} else {
-		m.textarea.SetValue(m.exercises[i].StarterCode)
-	}
+	m.supersedeRun()
+	m.stopLiveChecks()
+	m.loadBuffers()
+	m.marks = nil
+	m.consoleLines = nil
+	m.editSeq++
 	m.recalcEditorHeight()
 }
 
+// moveCursor puts the textarea cursor on the given line and column,
+// clamping the column to the length of the line.
+func (m *model) moveCursor(targetLine, targetCol int) {
+	// The textarea only moves the cursor a row at a time, and a long line
+	// may wrap onto several rows. There can't be more rows than characters.
+	maxSteps := len(m.textarea.Value()) + 1
+	for i := 0; m.textarea.Line() < targetLine && i < maxSteps; i++ {
+		m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	for i := 0; m.textarea.Line() > targetLine && i < maxSteps; i++ {
+		m.textarea, _ = m.textarea.Update(tea.KeyMsg{Type: tea.KeyUp})
+	}
+
+	// Clamp col to len of target line, set col
+	lines := strings.Split(m.textarea.Value(), "\n")
+	lineLen := len([]rune(lines[m.textarea.Line()]))
+	if targetCol > lineLen {
+		targetCol = lineLen
+	}
+
+	m.textarea.SetCursor(targetCol)
+
+	m.calculateCursorCoordinates()
+}
+
 func (m *model) calculateCursorCoordinates() {
 	curRow := m.textarea.Line()
 	viewHeight := m.textarea.Height()
@@ -605,23 +1006,36 @@ func (m *model) calculateCursorCoordinates() {
 	m.gutterWidth = numWidth + 1 // line numbers + space
 }
 
+// appendOutput adds lines to the output panel, keeping the last
+// maxBufferLines.
+func (m *model) appendOutput(lines ...runnerOutputMsg) {
+	m.outputLines = append(m.outputLines, lines...)
+	if len(m.outputLines) > maxBufferLines {
+		m.outputLines = m.outputLines[len(m.outputLines)-maxBufferLines:]
+	}
+	m.recalcEditorHeight()
+}
+
 func (m model) renderOutputPanel(boxHeight int) string {
 	style := outputStyle.Width(m.width - panelHorizChrome)
 
+	bar := m.renderOutputTabs()
 	if m.running {
-		spin := m.spinner.View()
-		lines := []string{spin + " Running..."}
-		for len(lines) < boxHeight {
-			lines = append(lines, "")
+		status := "Running..."
+		if m.currentTest != "" {
+			status = "Running " + m.currentTest + "..."
 		}
-		return style.Render(strings.Join(lines, "\n"))
+		bar += " " + m.spinner.View() + " " + status
 	}
 
 	lines := m.outputLines
-	if len(lines) > boxHeight {
-		lines = lines[len(lines)-boxHeight:]
+	if m.outputTab == consoleTab {
+		lines = m.consoleView()
 	}
-	for len(lines) < boxHeight {
+	height := boxHeight - 1 // less the tab bar
+	end := max(len(lines)-m.outputScroll, 0)
+	lines = lines[max(end-height, 0):end]
+	for len(lines) < height {
 		lines = append(lines, runnerOutputMsg{Line: ""})
 	}
 
@@ -634,13 +1048,34 @@ func (m model) renderOutputPanel(boxHeight int) string {
 		}
 	}
 
-	return style.Render(strings.Join(renderedLines, "\n"))
+	return style.Render(bar + "\n" + strings.Join(renderedLines, "\n"))
+}
+
+// editorHelp is the key help shown below the editor.
+func (m model) editorHelp() string {
+	chapterNav := "off"
+	if m.stayInChapter {
+		chapterNav = "on"
+	}
+	live := "off"
+	if m.liveCheck {
+		live = "on"
+	}
+	files := ""
+	if m.hasTabs() {
+		files = " | [F4] Next file"
+	}
+	run := "[F5] Run"
+	if m.running {
+		run = "[ctrl+x] Cancel run"
+	}
+	return "[esc] Back | " + run + " | [F2] Hint | [F3] Next ???" + files + " | [F7] Tests/Console | [F8] TS versions | [pgup/pgdn] Scroll output | [shift + ← / → ] Prev/Next Exercise | [ctrl+g] Stay in chapter: " + chapterNav + " | [ctrl+l] Live check: " + live
 }
 
 func (m model) View() string {
 	switch m.state {
 	case menu:
-		return m.list.View() + "\n\n[enter] Start | [q] Quit | [ ← / → ] Prev/Next Page | [/] Filter"
+		return m.list.View() + m.packErrorsBanner() + "\n\n[enter] Start / Expand or collapse chapter | [q] Quit | [ ← / → ] Prev/Next Page | [/] Filter (e.g. tag:generics level:1)"
 	case editor:
 		header := headerStyle.Render(m.exercises[m.selected].Title())
 		desc := descStyle.Render(m.exercises[m.selected].Description())
@@ -656,26 +1091,25 @@ func (m model) View() string {
 			debugPanel = debugStyle.Width(m.width - panelHorizChrome).Render(strings.Join(logs, "\n"))
 		}
 
-		help := helpStyle.Render("[esc] Back | [F5] Run | [shift + ← / → ] Prev/Next Exercise")
+		help := helpStyle.Width(m.width - panelHorizChrome).Render(m.editorHelp())
 
 		output := m.renderOutputPanel(m.outputHeight)
 
 		// Set editor size
-		infoChrome := 6 // infoStyle MarginLeft(2) + Border(1) + PaddingLeft(1) + right border(1) + safety(1)
-		editorWidth := (m.width - panelHorizChrome) * 60 / 100
-		editor := editorStyle.Width(editorWidth).Height(m.editorHeight).Render(m.renderHighlightedCode(m.editorHeight))
+		code := m.renderHighlightedCode(m.textarea.Height())
+		if m.hasTabs() {
+			code = m.renderTabBar() + "\n" + code
+		}
+		editor := editorStyle.Width(m.editorPanelWidth()).Height(m.editorHeight).Render(code)
 
 		// Join help text panel horizontally with editor (when enough width)
-		if m.width > 80 && m.exercises[m.selected].Info() != "" {
-			infoWidth := m.width - panelHorizChrome - editorWidth - infoChrome
-			if infoWidth > 10 {
-				info := infoStyle.Width(infoWidth).Height(m.editorHeight).Render(dedent.Dedent(m.exercises[m.selected].Info()))
-				editor = lipgloss.JoinHorizontal(lipgloss.Top, editor, info)
-			}
+		if m.infoPanelVisible() {
+			info := infoStyle.Width(m.infoPanelWidth()).Height(m.editorHeight).Render(m.infoText())
+			editor = lipgloss.JoinHorizontal(lipgloss.Top, editor, info)
 		}
 
 		// Compose
-		panels := []string{header, desc, editor, output}
+		panels := []string{header, desc, editor, m.renderStatusLine(), output}
 		if m.debugMode {
 			panels = append(panels, debugPanel)
 		}
@@ -705,45 +1139,105 @@ func tscAvailable() bool {
 	return err == nil
 }
 
-// tscCommand builds the tsc invocation. If TSKOANS_TSC is set (e.g. by the
+// tscCommand builds the tsc invocation for a generated tsconfig.json. If TSKOANS_TSC is set (e.g. by the
 // npm shim pointing at the bundled typescript package), invoke that script
 // via node so it works cross-platform. Otherwise fall back to a `tsc` binary
 // on PATH (for users running from source or a GitHub release).
-func tscCommand(typecheckPath, outDir string) *exec.Cmd {
-	args := []string{typecheckPath, "--target", "es2020", "--module", "commonjs", "--outDir", outDir}
+func tscCommand(ctx context.Context, tsconfigPath string) *exec.Cmd {
+	args := []string{"--project", tsconfigPath}
+	var cmd *exec.Cmd
 	if bundled := os.Getenv("TSKOANS_TSC"); bundled != "" {
-		return exec.Command("node", append([]string{bundled}, args...)...)
+		cmd = exec.CommandContext(ctx, "node", append([]string{bundled}, args...)...)
+	} else {
+		cmd = exec.CommandContext(ctx, "tsc", args...)
 	}
-	return exec.Command("tsc", args...)
+	killTreeOnCancel(cmd)
+	return cmd
 }
 
 func main() {
-	if !nodeAvailable() {
-		fmt.Fprintln(os.Stderr, "❌ Node.js not found in PATH. Please install Node.js (https://nodejs.org/) and try again.")
-		os.Exit(1)
+	config, err := internal.LoadConfig()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: Could not load config:", err)
 	}
 
-	if !tscAvailable() {
-		fmt.Fprintln(os.Stderr, "❌ tsc not found in PATH. Please install with `npm install -g typescript`.")
+	debug := flag.Bool("debug", false, "enable debug mode")
+	flag.DurationVar(&tscTimeout, "tsc-timeout", tscTimeout, "give up type-checking after this long")
+	noCache := flag.Bool("no-cache", false, "always compile and run, rather than replaying the results of an unchanged run")
+	typeScriptPaths = config.TypeScripts
+	if config.Backend == "" {
+		config.Backend = "tsc"
+	}
+	backendName := flag.String("backend", config.Backend, "how koans are compiled and run: "+strings.Join(backendNames, ", "))
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: tskoans [flags]\n       tskoans validate [exercise-id...]\n\nFlags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	runCacheEnabled = !*noCache
+	activeBackend, err = backendNamed(*backendName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		os.Exit(1)
+	}
+	err = activeBackend.Check()
+	if errors.Is(err, errNodeMissing) && errors.Is((tsgoBackend{}).Check(), errNodeMissing) {
+		// tsgo needs no Node.js, so type-only koans can still be solved.
+		fmt.Fprintln(os.Stderr, "Warning: Node.js not found in PATH. Using tsgo, so only type-only koans can be solved.")
+		activeBackend, nodeMissing, err = tsgoBackend{}, true, nil
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
 		os.Exit(1)
 	}
+	_, usesWorker := activeBackend.(tscBackend)
 
-	debug := flag.Bool("debug", false, "enable debug mode")
-	flag.Parse()
+	exs, packErrs := internal.Catalog()
+	if flag.Arg(0) == "validate" {
+		// No compiler worker: it takes one request at a time, and validate
+		// runs many at once, each with its own tsc.
+		os.Exit(runValidate(exs, packErrs, flag.Args()[1:], os.Stdout))
+	}
 
 	state, err := internal.LoadState()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Warning: Could not load previous state:", err)
 	}
-	m := initialModel(state)
+
+	m := initialModel(state, exs)
 	m.debugMode = *debug
+	m.packErrs = packErrs
 	m.debugLog = append(m.debugLog, "Debug panel is working!")
+	for _, err := range packErrs {
+		m.debugLog = append(m.debugLog, "pack: "+err.Error())
+	}
 
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 	go func() {
 		p.Send(setProgramMsg{program: p})
 	}()
-	if _, err := p.Run(); err != nil {
+	go func() {
+		for _, v := range activeBackend.Versions() {
+			p.Send(runnerDebugMsg{Line: "backend: " + v})
+		}
+	}()
+	// Runs use a one-shot tsc until the worker is up.
+	go func() {
+		if !usesWorker {
+			return
+		}
+		w, err := startTSWorker()
+		if err != nil {
+			p.Send(runnerDebugMsg{Line: "Using tsc for every run: " + err.Error()})
+			return
+		}
+		compilerWorker.Store(w)
+		p.Send(runnerDebugMsg{Line: "tsc worker ready, TypeScript " + w.Version})
+	}()
+	_, err = p.Run()
+	compilerWorker.Load().Close()
+	if err != nil {
 		fmt.Println("Error running program:", err)
 		os.Exit(1)
 	}
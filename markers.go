@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Inline error markers ---
+//
+// After a failed compile, lines with errors get a ● in the gutter and the
+// offending name is underlined. The status line under the editor shows the
+// error on the cursor's line, or a summary of where the errors are. Marks
+// stay until the next run or live check, even if the code has changed since.
+
+const (
+	statusLineHeight = 1
+	maxStatusLines   = 5 // line numbers listed in the status summary
+)
+
+var (
+	errorMarkerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	statusLineStyle  = lipgloss.NewStyle().PaddingLeft(6).Foreground(lipgloss.Color("9"))
+)
+
+// squiggle restyles the text an error points at.
+func squiggle(s lipgloss.Style) lipgloss.Style {
+	return s.Underline(true).Foreground(lipgloss.Color("9"))
+}
+
+// activeFileName is the name diagnostics use for the file in the editor.
+func (m model) activeFileName() string {
+	if m.activeFile == 0 {
+		return "typecheck.ts"
+	}
+	return m.exercises[m.selected].Files[m.activeFile-1].Name
+}
+
+// tabName is the name a file is shown under in the tab bar.
+func tabName(file string) string {
+	if file == "typecheck.ts" {
+		return mainFileTab
+	}
+	return file
+}
+
+// activeMarks returns the marks in the file in the editor, by line.
+func (m model) activeMarks() map[int][]editorMark {
+	byLine := make(map[int][]editorMark)
+	name := m.activeFileName()
+	for _, mk := range m.marks {
+		if mk.File == name {
+			byLine[mk.Line] = append(byLine[mk.Line], mk)
+		}
+	}
+	return byLine
+}
+
+// markRanges returns the columns to underline for the marks on one line.
+func markRanges(marks []editorMark) []colRange {
+	ranges := make([]colRange, len(marks))
+	for i, mk := range marks {
+		ranges[i] = colRange{start: mk.Col, end: mk.Col + mk.Len}
+	}
+	return ranges
+}
+
+// gutterMarker is drawn between a line's number and its code.
+func gutterMarker(marks []editorMark) string {
+	if len(marks) == 0 {
+		return " "
+	}
+	return errorMarkerStyle.Render("●")
+}
+
+// statusLine describes the error on the cursor's line, or else where the
+// errors are. It is empty when there are none.
+func (m model) statusLine() string {
+	if len(m.marks) == 0 {
+		return ""
+	}
+	if here := m.activeMarks()[m.textarea.Line()]; len(here) > 0 {
+		text := "● " + strings.ReplaceAll(here[0].Message, "\n", " ")
+		if len(here) > 1 {
+			text += fmt.Sprintf(" (+%d more)", len(here)-1)
+		}
+		return text
+	}
+
+	var places []string
+	for i, mk := range m.marks {
+		if i == maxStatusLines {
+			places = append(places, "…")
+			break
+		}
+		place := fmt.Sprintf("line %d", mk.Line+1)
+		if m.hasTabs() {
+			place = fmt.Sprintf("%s:%d", tabName(mk.File), mk.Line+1)
+		}
+		places = append(places, place)
+	}
+	noun := "error"
+	if len(m.marks) != 1 {
+		noun = "errors"
+	}
+	return fmt.Sprintf("● %d %s (%s). Move the cursor to a marked line to see it.", len(m.marks), noun, strings.Join(places, ", "))
+}
+
+func (m model) renderStatusLine() string {
+	return statusLineStyle.MaxWidth(m.width).Render(m.statusLine())
+}
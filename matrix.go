@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- TypeScript version matrix ---
+//
+// [F8] type-checks the code in the editor with every TypeScript install
+// ts-koans can find, and shows which versions accept it. Installs are
+// looked for in the session's own TypeScript, ./node_modules, and the
+// "typescripts" paths in ~/.ts-koans/config.json, each of which can be a
+// typescript package, a node_modules directory or a project holding one.
+// Only the type-check runs; the tests don't depend on the version.
+
+// typeScriptPaths are the configured places to look for TypeScript installs.
+var typeScriptPaths []string
+
+// tsInstall is a typescript package on disk.
+type tsInstall struct {
+	Version string
+	Dir     string
+}
+
+// typeScriptAt returns the typescript package in dir, if there is one.
+func typeScriptAt(dir string) (tsInstall, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return tsInstall{}, false
+	}
+	var pkg struct{ Name, Version string }
+	if json.Unmarshal(data, &pkg) != nil || pkg.Name != "typescript" {
+		return tsInstall{}, false
+	}
+	return tsInstall{Version: pkg.Version, Dir: dir}, true
+}
+
+// findTypeScripts returns the TypeScript installs found in paths and the
+// default places, newest first and without duplicates.
+func findTypeScripts(paths []string) []tsInstall {
+	candidates := []string{filepath.Join("node_modules", "typescript")}
+	if dir, err := typeScriptPackageDir(); err == nil {
+		candidates = append(candidates, dir)
+	}
+	for _, p := range paths {
+		if strings.HasPrefix(p, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				p = filepath.Join(home, p[2:])
+			}
+		}
+		candidates = append(candidates, p, filepath.Join(p, "typescript"), filepath.Join(p, "node_modules", "typescript"))
+	}
+
+	seen := map[string]bool{}
+	var installs []tsInstall
+	for _, c := range candidates {
+		install, ok := typeScriptAt(c)
+		if !ok {
+			continue
+		}
+		if real, err := filepath.EvalSymlinks(c); err == nil {
+			install.Dir = real
+		}
+		if abs, err := filepath.Abs(install.Dir); err == nil {
+			install.Dir = abs
+		}
+		if seen[install.Dir] {
+			continue
+		}
+		seen[install.Dir] = true
+		installs = append(installs, install)
+	}
+	sort.SliceStable(installs, func(i, j int) bool {
+		return compareVersions(installs[i].Version, installs[j].Version) > 0
+	})
+	return installs
+}
+
+// compareVersions compares dotted version numbers, e.g. "5.4.5" and
+// "5.10.0-beta", by their numeric parts.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(strings.TrimRight(as[i], "-+abcdefghijklmnopqrstuvwxyz"))
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(strings.TrimRight(bs[i], "-+abcdefghijklmnopqrstuvwxyz"))
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// pinnedTSC is the tsc backend with one particular TypeScript install,
+// run by node without the compiler worker.
+type pinnedTSC struct {
+	tscBackend
+	install tsInstall
+}
+
+func (b pinnedTSC) Compile(ctx context.Context, tsconfigPath, dir string) (string, string, error) {
+	cmd := exec.CommandContext(ctx, "node", filepath.Join(b.install.Dir, "bin", "tsc"), "--project", tsconfigPath)
+	cmd.Dir = dir
+	killTreeOnCancel(cmd)
+	return runTool(cmd)
+}
+
+// tsOptionErrorPattern matches tsc rejecting a compiler option it doesn't
+// know (TS5023), or a value it doesn't (TS5024, TS6046), as older versions
+// do with some that ts-koans sets, such as moduleDetection.
+var tsOptionErrorPattern = regexp.MustCompile(`\bTS(?:5023|5024|6046): (.*)$`)
+
+// errUnsupportedOptions is returned by typeCheckWith when the TypeScript
+// version can't take the koan's compiler options.
+var errUnsupportedOptions = errors.New("compiler options unsupported by this version")
+
+// matrixDoneMsg ends a version matrix run.
+type matrixDoneMsg struct{}
+
+// runVersionMatrix type-checks userCode with each install in turn, sending
+// a row of the matrix for each.
+func runVersionMatrix(ctx context.Context, cancel context.CancelFunc, program runSender, userCode string, files []internal.SourceFile, ex internal.Exercise) tea.Cmd {
+	return func() tea.Msg {
+		defer cancel()
+		defer program.Send(matrixDoneMsg{})
+		if blanks := describeAllBlanks(userCode, files); blanks != "" {
+			program.Send(runnerOutputMsg{Line: fmt.Sprintf("✋ %s. Fill them in first ([F3] jumps to the next one).", blanks)})
+			return nil
+		}
+		if !nodeAvailable() {
+			program.Send(runnerOutputMsg{Line: "The version matrix runs each TypeScript with Node.js, which isn't in PATH."})
+			return nil
+		}
+		installs := findTypeScripts(typeScriptPaths)
+		if len(installs) == 0 {
+			program.Send(runnerOutputMsg{Line: `No TypeScript installs found. List some under "typescripts" in ~/.ts-koans/config.json.`})
+			return nil
+		}
+		program.Send(runnerOutputMsg{Line: fmt.Sprintf("TypeScript versions for %q:", ex.Title())})
+		accepted, unsupported := 0, 0
+		for _, install := range installs {
+			program.Send(runnerDebugMsg{Line: fmt.Sprintf("matrix: TypeScript %s in %s", install.Version, install.Dir)})
+			program.Send(testStartMsg{Name: "TypeScript " + install.Version})
+			result, err := typeCheckWith(ctx, pinnedTSC{install: install}, userCode, files, ex)
+			if errors.Is(err, context.Canceled) {
+				program.Send(runnerOutputMsg{Line: "⏹ Run cancelled."})
+				return nil
+			}
+			switch {
+			case err == nil:
+				accepted++
+			case errors.Is(err, errUnsupportedOptions):
+				unsupported++
+			}
+			program.Send(runnerOutputMsg{Line: fmt.Sprintf("  %-12s %s", install.Version, result)})
+		}
+		summary := fmt.Sprintf("%d of %d versions accept this code.", accepted, len(installs))
+		if unsupported > 0 {
+			summary = fmt.Sprintf("%d of %d versions accept this code; %d can't compile this koan at all.", accepted, len(installs)-unsupported, unsupported)
+		}
+		program.Send(runnerOutputMsg{Line: summary})
+		return nil
+	}
+}
+
+// typeCheckWith runs compileTypeScript with backend b, and sums up the
+// result in a line. If the version rejects the koan's compiler options, the
+// code wasn't judged, and errUnsupportedOptions is returned.
+func typeCheckWith(ctx context.Context, b backend, userCode string, files []internal.SourceFile, ex internal.Exercise) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "tskoans-*")
+	if err != nil {
+		return fmt.Sprintf("❌ Failed to create temp dir: %v", err), err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	var out outputCollector
+	err = compileTypeScript(withBackend(ctx, b), tmpDir, userCode, files, withNoEmit(ex), &out)
+	switch {
+	case err == nil:
+		return "✅ type-checks", nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("⏱ timed out after %s", tscTimeout), err
+	}
+	for _, msg := range out.msgs {
+		if m := tsOptionErrorPattern.FindStringSubmatch(msg.Line); m != nil {
+			return "➖ unsupported by this version: " + m[1], errUnsupportedOptions
+		}
+	}
+	line := out.firstLine()
+	if !strings.HasPrefix(line, "❌") {
+		line = "❌ " + line
+	}
+	return line, err
+}
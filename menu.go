@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Exercise menu ---
+//
+// The menu groups exercises under collapsible chapter headers. Both kinds of
+// row are list items, so the list widget still handles paging, filtering
+// and selection for us.
+//
+// Exercise rows carry badges for their level, time estimate and tags, and
+// the / filter understands tag:<tag> and level:<level> alongside the usual
+// fuzzy title match, e.g. "tag:generics level:beginner".
+
+// defaultChapter is used for exercises that don't name a chapter.
+const defaultChapter = "Other"
+
+// filterMetaSep separates an exercise's title from its metadata in
+// FilterValue. Fuzzy matching only ever sees the part before it.
+const filterMetaSep = "\x1f"
+
+var (
+	levelBadgeStyles = map[int]lipgloss.Style{
+		internal.LevelBeginner:     lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("2")).Padding(0, 1),
+		internal.LevelIntermediate: lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("3")).Padding(0, 1),
+		internal.LevelAdvanced:     lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("1")).Padding(0, 1),
+	}
+	minutesBadgeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	tagBadgeStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+	packErrorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// maxPackErrorLines is how many pack errors are listed under the menu; the
+// debug panel has them all.
+const maxPackErrorLines = 3
+
+// chapterItem is a header row in the menu. Selecting it toggles whether the
+// chapter's exercises are shown.
+type chapterItem struct {
+	name      string
+	done      int
+	total     int
+	collapsed bool
+}
+
+func (c chapterItem) Title() string {
+	arrow := "▾"
+	if c.collapsed {
+		arrow = "▸"
+	}
+	return fmt.Sprintf("%s %s", arrow, c.name)
+}
+func (c chapterItem) Description() string {
+	return fmt.Sprintf("%d/%d completed", c.done, c.total)
+}
+func (c chapterItem) FilterValue() string { return c.name }
+
+// exerciseItem is an exercise row in the menu, remembering its position in
+// m.exercises so the selection can be mapped back to it.
+type exerciseItem struct {
+	internal.Exercise
+	index int
+}
+
+// FilterValue is the title, then filterMetaSep and the tokens that tag: and
+// level: queries match, e.g. "Generics: function\x1flevel:1 tag:generics".
+func (e exerciseItem) FilterValue() string {
+	var meta []string
+	if e.Level > 0 {
+		meta = append(meta, "level:"+strconv.Itoa(e.Level))
+	}
+	for _, t := range e.Tags {
+		meta = append(meta, "tag:"+strings.ToLower(t))
+	}
+	return e.Exercise.FilterValue() + filterMetaSep + strings.Join(meta, " ")
+}
+
+// badges renders the exercise's level, time estimate and tags.
+func (e exerciseItem) badges() string {
+	var parts []string
+	if name := internal.LevelName(e.Level); name != "" {
+		parts = append(parts, levelBadgeStyles[e.Level].Render(name))
+	}
+	if e.Minutes > 0 {
+		parts = append(parts, minutesBadgeStyle.Render(fmt.Sprintf("~%d min", e.Minutes)))
+	}
+	for _, t := range e.Tags {
+		parts = append(parts, tagBadgeStyle.Render("#"+t))
+	}
+	return strings.Join(parts, " ")
+}
+
+// menuDelegate is the default list delegate, with badges after each
+// exercise's title when there is room for them.
+type menuDelegate struct {
+	list.DefaultDelegate
+}
+
+func newMenuDelegate() menuDelegate {
+	return menuDelegate{list.NewDefaultDelegate()}
+}
+
+func (d menuDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	ex, ok := item.(exerciseItem)
+	if !ok {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+	var b strings.Builder
+	d.DefaultDelegate.Render(&b, m, index, item)
+	title, rest, hasDesc := strings.Cut(b.String(), "\n")
+	if badges := ex.badges(); badges != "" && lipgloss.Width(title)+1+lipgloss.Width(badges) <= m.Width() {
+		title += " " + badges
+	}
+	if hasDesc {
+		title += "\n" + rest
+	}
+	fmt.Fprint(w, title)
+}
+
+// menuQuery is a parsed / filter: free text for fuzzy title matching, plus
+// tag: and level: terms that every match must satisfy.
+type menuQuery struct {
+	text  string
+	tags  []string // lowercase tag prefixes
+	level int
+}
+
+func parseMenuQuery(term string) menuQuery {
+	var q menuQuery
+	var words []string
+	for _, w := range strings.Fields(term) {
+		key, value, ok := strings.Cut(w, ":")
+		isTerm := ok && (strings.EqualFold(key, "tag") || strings.EqualFold(key, "level"))
+		switch {
+		case isTerm && value == "":
+			// Still being typed.
+			continue
+		case ok && strings.EqualFold(key, "tag"):
+			q.tags = append(q.tags, strings.ToLower(value))
+			continue
+		case ok && strings.EqualFold(key, "level"):
+			if level, ok := internal.ParseLevel(value); ok {
+				q.level = level
+				continue
+			}
+		}
+		words = append(words, w)
+	}
+	q.text = strings.Join(words, " ")
+	return q
+}
+
+// matches reports whether an item's metadata (the part of its FilterValue
+// after filterMetaSep) satisfies the tag: and level: terms. A tag term
+// matches any tag it is a prefix of, so results narrow as you type.
+func (q menuQuery) matches(meta string) bool {
+	fields := strings.Fields(meta)
+	if q.level > 0 && !slices.Contains(fields, "level:"+strconv.Itoa(q.level)) {
+		return false
+	}
+	for _, t := range q.tags {
+		if !slices.ContainsFunc(fields, func(f string) bool { return strings.HasPrefix(f, "tag:"+t) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterMenu is the list's FilterFunc. It drops items that fail the tag:
+// and level: terms, then fuzzy-matches the rest of the query against the
+// titles the way list.DefaultFilter does.
+func filterMenu(term string, targets []string) []list.Rank {
+	q := parseMenuQuery(term)
+	var titles []string
+	var indexes []int
+	for i, t := range targets {
+		title, meta, _ := strings.Cut(t, filterMetaSep)
+		if (q.level > 0 || len(q.tags) > 0) && !q.matches(meta) {
+			continue
+		}
+		titles = append(titles, title)
+		indexes = append(indexes, i)
+	}
+	if q.text == "" {
+		ranks := make([]list.Rank, len(indexes))
+		for i, idx := range indexes {
+			ranks[i] = list.Rank{Index: idx}
+		}
+		return ranks
+	}
+	ranks := list.DefaultFilter(q.text, titles)
+	for i := range ranks {
+		ranks[i].Index = indexes[ranks[i].Index]
+	}
+	return ranks
+}
+
+func chapterOf(ex internal.Exercise) string {
+	if ex.Chapter() == "" {
+		return defaultChapter
+	}
+	return ex.Chapter()
+}
+
+// chapterOrder returns chapter names in the order they first appear, along
+// with the exercise indexes belonging to each chapter.
+func chapterOrder(exs []internal.Exercise) ([]string, map[string][]int) {
+	var names []string
+	members := make(map[string][]int)
+	for i, ex := range exs {
+		ch := chapterOf(ex)
+		if _, ok := members[ch]; !ok {
+			names = append(names, ch)
+		}
+		members[ch] = append(members[ch], i)
+	}
+	return names, members
+}
+
+// makeListItems builds the menu rows. Solved exercises get a ✅, and a ✨ as
+// well if they were solved without hints.
+func makeListItems(exs []internal.Exercise, state internal.PersistentState, collapsed map[string]bool) []list.Item {
+	completed := state.Completed
+	names, members := chapterOrder(exs)
+	var items []list.Item
+	for _, ch := range names {
+		header := chapterItem{name: ch, total: len(members[ch]), collapsed: collapsed[ch]}
+		for _, i := range members[ch] {
+			if completed[exs[i].ID] {
+				header.done++
+			}
+		}
+		items = append(items, header)
+		if header.collapsed {
+			continue
+		}
+		for _, i := range members[ch] {
+			label := exs[i].Title()
+			if completed[exs[i].ID] {
+				label = "✅ " + label
+				if state.Unaided[exs[i].ID] {
+					label += " ✨"
+				}
+			}
+			ex := exs[i]
+			ex.Label = label
+			items = append(items, exerciseItem{Exercise: ex, index: i})
+		}
+	}
+	return items
+}
+
+// setMenuItems rebuilds the menu items. While a filter is in use it
+// searches every exercise, so collapsed chapters are expanded, and it is
+// applied to the new items straight away.
+func (m *model) setMenuItems() {
+	collapsed := m.collapsed
+	if m.list.FilterState() != list.Unfiltered {
+		collapsed = nil
+	}
+	if cmd := m.list.SetItems(makeListItems(m.exercises, m.persistentState, collapsed)); cmd != nil {
+		m.list, _ = m.list.Update(cmd())
+	}
+}
+
+// refreshMenu rebuilds the menu items, keeping the cursor on the same row.
+func (m *model) refreshMenu() {
+	idx := m.list.Index()
+	m.setMenuItems()
+	m.list.Select(idx)
+}
+
+// toggleChapter collapses or expands a chapter and keeps its header selected.
+func (m *model) toggleChapter(name string) {
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[name] = !m.collapsed[name]
+	m.setMenuItems()
+	for i, item := range m.list.VisibleItems() {
+		if c, ok := item.(chapterItem); ok && c.name == name {
+			m.list.Select(i)
+			break
+		}
+	}
+}
+
+// neighbourExercise returns the exercise delta steps away from the current
+// one, wrapping around. With stayInChapter it only moves within the
+// current exercise's chapter.
+func (m model) neighbourExercise(delta int) int {
+	if !m.stayInChapter {
+		n := len(m.exercises)
+		return ((m.selected+delta)%n + n) % n
+	}
+	_, members := chapterOrder(m.exercises)
+	siblings := members[chapterOf(m.exercises[m.selected])]
+	for pos, i := range siblings {
+		if i == m.selected {
+			n := len(siblings)
+			return siblings[((pos+delta)%n+n)%n]
+		}
+	}
+	return m.selected
+}
+
+// packErrorsBanner lists the koan pack entries that were skipped, to go
+// under the menu, or returns "" if there were none.
+func (m model) packErrorsBanner() string {
+	if len(m.packErrs) == 0 {
+		return ""
+	}
+	var lines []string
+	for i, err := range m.packErrs {
+		if i == maxPackErrorLines {
+			lines = append(lines, fmt.Sprintf("⚠ …and %d more (start with -debug to see them all)", len(m.packErrs)-i))
+			break
+		}
+		lines = append(lines, "⚠ Skipped invalid koan pack entry: "+err.Error())
+	}
+	return "\n" + packErrorStyle.Width(m.width).Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os/exec"
+	"time"
+)
+
+// --- Child processes ---
+//
+// tsc and node are started in a process group of their own, so that a
+// cancelled or timed-out run takes down anything they started too. The
+// platform parts are in proc_unix.go and proc_windows.go.
+
+// processWaitDelay bounds how long Wait waits for output after a kill.
+const processWaitDelay = time.Second
+
+// killTreeOnCancel makes cmd, which must have been made with
+// exec.CommandContext, kill its whole process tree when the context is
+// done.
+func killTreeOnCancel(cmd *exec.Cmd) {
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessTree(cmd) }
+	cmd.WaitDelay = processWaitDelay
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Run IDs ---
+//
+// Every F5 starts a run with a new ID, and everything the run sends is
+// wrapped in a runMsg carrying that ID and the exercise it ran. Switching
+// exercises or leaving the editor supersedes the run in flight: it carries
+// on, and a pass still counts for the exercise it ran, but its output is
+// dropped instead of landing on whatever is in the editor now.
+
+// runMsg is a message from run Run of exercise ExerciseID.
+type runMsg struct {
+	Run        int
+	ExerciseID string
+	Msg        tea.Msg
+}
+
+// runSender tags messages with the run they come from.
+type runSender struct {
+	program    msgSender
+	run        int
+	exerciseID string
+}
+
+func (s runSender) Send(msg tea.Msg) {
+	s.program.Send(runMsg{Run: s.run, ExerciseID: s.exerciseID, Msg: msg})
+}
+
+// updateRun handles a message from a run, current or not.
+func (m model) updateRun(msg runMsg) (tea.Model, tea.Cmd) {
+	if done, ok := msg.Msg.(runnerDoneMsg); ok && done.Err == nil {
+		m.markCompleted(msg.ExerciseID)
+	}
+	if msg.Run == m.runID && m.state == editor {
+		return m.updateEditor(msg.Msg)
+	}
+	if debug, ok := msg.Msg.(runnerDebugMsg); ok {
+		m.appendDebug(debug.Line)
+	}
+	return m, nil
+}
+
+// supersedeRun detaches the run in flight, if any, from the editor.
+func (m *model) supersedeRun() {
+	m.runID++
+	m.running = false
+	m.cancelRun = nil
+	m.currentTest = ""
+}
+
+// markCompleted records that exercise id was solved.
+func (m *model) markCompleted(id string) {
+	if m.persistentState.Completed == nil {
+		m.persistentState.Completed = make(map[string]bool)
+	}
+	m.persistentState.Completed[id] = true
+	if m.persistentState.HintsUsed[id] == 0 {
+		if m.persistentState.Unaided == nil {
+			m.persistentState.Unaided = make(map[string]bool)
+		}
+		m.persistentState.Unaided[id] = true
+	}
+	m.refreshMenu()
+	m.saveState()
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Node sandbox ---
+//
+// The tests run in a vm context (see runner.mjs), and node itself runs
+// under its permission model: it can read the run's temp directory and
+// nothing else, and can't write files, start processes or load addons. Its
+// heap is capped too. The permission model is --permission from Node 22.13
+// and --experimental-permission before that; without either, the vm
+// sandbox is all there is.
+
+const nodeMemoryLimitMB = 128
+
+// nodePermissionFlags returns the flags that turn on node's permission
+// model, found by trying them once per session.
+var nodePermissionFlags = sync.OnceValue(func() []string {
+	for _, flag := range []string{"--permission", "--experimental-permission"} {
+		if exec.Command("node", flag, "-e", "").Run() != nil {
+			continue
+		}
+		flags := []string{flag}
+		// On Windows the runner reports over a loopback connection, which
+		// newer versions of the permission model block by default.
+		if runtime.GOOS == "windows" && exec.Command("node", flag, "--allow-net", "-e", "").Run() == nil {
+			flags = append(flags, "--allow-net")
+		}
+		return flags
+	}
+	return nil
+})
+
+// nodeSandboxArgs are the arguments to run script from dir in the sandbox.
+func nodeSandboxArgs(dir, script string) []string {
+	// --experimental-vm-modules lets runner.mjs run ES module koans.
+	args := []string{fmt.Sprintf("--max-old-space-size=%d", nodeMemoryLimitMB), "--no-warnings", "--experimental-vm-modules"}
+	if perm := nodePermissionFlags(); perm != nil {
+		// The permission model wants real paths, e.g. /private/var rather
+		// than /var on macOS.
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			dir = real
+		}
+		args = append(args, perm...)
+		args = append(args, "--allow-fs-read="+dir)
+	}
+	return append(args, script)
+}
+
+// sandboxViolation explains what node printed to stderr when it was
+// stopped by one of the sandbox's limits, or returns "".
+func sandboxViolation(stderr string) string {
+	switch {
+	case strings.Contains(stderr, "heap out of memory"):
+		return fmt.Sprintf("🔒 The tests ran out of memory (the limit is %d MB). Is something growing without end?", nodeMemoryLimitMB)
+	case strings.Contains(stderr, "ERR_ACCESS_DENIED"):
+		return "🔒 Node's permission model stopped the tests from reaching outside their folder."
+	case strings.Contains(stderr, "NotCapable"), strings.Contains(stderr, "PermissionDenied"):
+		return "🔒 Deno's permissions stopped the tests from reaching outside their folder."
+	}
+	return ""
+}
+
+// vmProbeTimeout is how long vmprobe.mjs gets. It only takes long if vm's
+// timeout doesn't work, and the loop it tries never ends.
+const vmProbeTimeout = 5 * time.Second
+
+// checkVMSandbox runs vmprobe.mjs with b's runtime, named name, and returns
+// an error saying what its node:vm lacks, if it lacks anything runner.mjs
+// needs to keep koan code in the sandbox. Node's has it all; Deno and Bun
+// implement node:vm themselves.
+func checkVMSandbox(b backend, name string) error {
+	dir, err := os.MkdirTemp("", "tskoans-probe-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "vmprobe.mjs")
+	if err := os.WriteFile(script, []byte(internal.VMProbeMJS), 0644); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vmProbeTimeout)
+	defer cancel()
+	cmd := b.TestCommand(ctx, dir, script)
+	cmd.Dir = dir
+	killTreeOnCancel(cmd)
+	stdout, stderr, err := runTool(cmd)
+	var problems []string
+	done := false
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		switch line = strings.TrimSpace(line); line {
+		case "":
+		case "done":
+			done = true
+		default:
+			problems = append(problems, line)
+		}
+	}
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		problems = append(problems, "an endless loop isn't stopped by vm's timeout")
+	case !done:
+		if msg := strings.TrimSpace(stderr); msg != "" {
+			err = errors.New(msg)
+		} else if err == nil {
+			err = errors.New("no result")
+		}
+		return fmt.Errorf("couldn't check %s's node:vm sandbox: %w", name, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s can't sandbox koan code: %s. Please use another backend.", name, strings.Join(problems, "; "))
+	}
+	return nil
+}
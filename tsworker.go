@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Persistent compiler worker ---
+//
+// Running tsc for every F5 loads TypeScript and parses the lib.*.d.ts files
+// from scratch each time. Instead, one node process running tsworker.cjs is
+// started per session and kept around; the tsc backend hands it the
+// generated tsconfig.json over stdin and reads tsc-style output back from
+// stdout. Until the worker is ready, or if it can't start or dies, the
+// backend falls back to a one-shot tsc. Other backends don't use it.
+
+const (
+	tsWorkerStartTimeout = 15 * time.Second
+	tsWorkerMaxLine      = 16 << 20 // longest response line we accept
+)
+
+// compilerWorker is the session's worker, or nil while there is none.
+var compilerWorker atomic.Pointer[tsWorker]
+
+// errTSWorkerFailed means the worker couldn't compile a project, and tsc
+// should be used instead.
+var errTSWorkerFailed = errors.New("tsc worker failed")
+
+type tsWorker struct {
+	busy    chan struct{} // holds a token while a request is in flight
+	cmd     *exec.Cmd
+	dir     string // holds the worker script
+	stdin   io.WriteCloser
+	stdout  *bufio.Scanner
+	nextID  int
+	broken  bool
+	Version string // TypeScript version the worker loaded
+}
+
+type tsWorkerRequest struct {
+	ID      int    `json:"id"`
+	Project string `json:"project"`
+}
+
+type tsWorkerResponse struct {
+	ID       int    `json:"id"`
+	Ready    bool   `json:"ready"`
+	Version  string `json:"version"`
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output"`
+	Error    string `json:"error"`
+}
+
+// typeScriptPackageDir finds the typescript package the tsc command comes
+// from: TSKOANS_TSC or tsc on PATH is <package>/bin/tsc, possibly behind a
+// symlink.
+func typeScriptPackageDir() (string, error) {
+	bin := os.Getenv("TSKOANS_TSC")
+	if bin == "" {
+		path, err := exec.LookPath("tsc")
+		if err != nil {
+			return "", err
+		}
+		bin = path
+	}
+	bin, err := filepath.EvalSymlinks(bin)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(filepath.Dir(bin))
+	if _, err := os.Stat(filepath.Join(dir, "package.json")); err != nil {
+		return "", fmt.Errorf("no typescript package found for %s", bin)
+	}
+	return dir, nil
+}
+
+// startTSWorker starts a worker and waits until it has loaded TypeScript.
+func startTSWorker() (*tsWorker, error) {
+	pkg, err := typeScriptPackageDir()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "tskoans-worker-*")
+	if err != nil {
+		return nil, err
+	}
+	script := filepath.Join(dir, "tsworker.cjs")
+	if err := os.WriteFile(script, []byte(internal.TSWorkerJS), 0644); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	w := &tsWorker{cmd: exec.Command("node", script, pkg), dir: dir, busy: make(chan struct{}, 1)}
+	setProcessGroup(w.cmd)
+	w.stdin, err = w.cmd.StdinPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	stdout, err := w.cmd.StdoutPipe()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	w.stdout = bufio.NewScanner(stdout)
+	w.stdout.Buffer(make([]byte, 64*1024), tsWorkerMaxLine)
+	if err := w.cmd.Start(); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		resp, err := w.read()
+		if err == nil && !resp.Ready {
+			err = errors.New("unexpected first message")
+		}
+		w.Version = resp.Version
+		ready <- err
+	}()
+	select {
+	case err = <-ready:
+	case <-time.After(tsWorkerStartTimeout):
+		err = errors.New("timed out loading TypeScript")
+	}
+	if err != nil {
+		w.Close()
+		return nil, fmt.Errorf("start tsc worker: %w", err)
+	}
+	return w, nil
+}
+
+func (w *tsWorker) read() (tsWorkerResponse, error) {
+	var resp tsWorkerResponse
+	if !w.stdout.Scan() {
+		if err := w.stdout.Err(); err != nil {
+			return resp, err
+		}
+		return resp, io.EOF
+	}
+	err := json.Unmarshal(w.stdout.Bytes(), &resp)
+	return resp, err
+}
+
+// compile type-checks and emits the project at tsconfigPath. The output is
+// what tsc would print; err is non-nil if there were errors, and wraps
+// errTSWorkerFailed if the worker couldn't do the job. If ctx is done while
+// waiting for another request, ctx.Err() is returned; if it is done during
+// this one, the worker is killed, since it can't be interrupted
+// mid-compile, and replaced in the background.
+func (w *tsWorker) compile(ctx context.Context, tsconfigPath string) (string, error) {
+	select {
+	case w.busy <- struct{}{}:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	defer func() { <-w.busy }()
+	if w.broken {
+		return "", errTSWorkerFailed
+	}
+
+	w.nextID++
+	req, _ := json.Marshal(tsWorkerRequest{ID: w.nextID, Project: tsconfigPath})
+	if _, err := w.stdin.Write(append(req, '\n')); err != nil {
+		w.broken = true
+		return "", fmt.Errorf("%w: %v", errTSWorkerFailed, err)
+	}
+	type result struct {
+		resp tsWorkerResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := w.read()
+		done <- result{resp, err}
+	}()
+	var resp tsWorkerResponse
+	var err error
+	select {
+	case r := <-done:
+		resp, err = r.resp, r.err
+	case <-ctx.Done():
+		w.broken = true
+		killProcessTree(w.cmd)
+		<-done
+		go replaceTSWorker(w)
+		return "", ctx.Err()
+	}
+	switch {
+	case err != nil:
+		w.broken = true
+		return "", fmt.Errorf("%w: %v", errTSWorkerFailed, err)
+	case resp.ID != w.nextID:
+		w.broken = true
+		return "", fmt.Errorf("%w: response %d to request %d", errTSWorkerFailed, resp.ID, w.nextID)
+	case resp.Error != "":
+		return "", fmt.Errorf("%w: %s", errTSWorkerFailed, resp.Error)
+	case resp.ExitCode != 0:
+		return resp.Output, fmt.Errorf("exit status %d", resp.ExitCode)
+	}
+	return resp.Output, nil
+}
+
+// Close stops the worker and removes its script.
+func (w *tsWorker) Close() {
+	if w == nil {
+		return
+	}
+	w.stdin.Close()
+	done := make(chan struct{})
+	go func() {
+		w.cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		killProcessTree(w.cmd)
+		<-done
+	}
+	os.RemoveAll(w.dir)
+}
+
+// replaceTSWorker swaps w, which was killed, for a new worker. Runs use a
+// one-shot tsc in the meantime.
+func replaceTSWorker(w *tsWorker) {
+	if !compilerWorker.CompareAndSwap(w, nil) {
+		return
+	}
+	w.Close()
+	nw, err := startTSWorker()
+	if err != nil {
+		return
+	}
+	if !compilerWorker.CompareAndSwap(nil, nw) {
+		nw.Close()
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/chris0lsen/ts-koans/internal"
+)
+
+// --- Catalog self-verification (`tskoans validate`) ---
+//
+// Every exercise is run twice through the same pipeline the editor uses:
+// its reference Solution must pass, and its untouched StarterCode must fail.
+// Otherwise the koan is either unsolvable or already solved.
+
+// outputCollector is a msgSender that keeps output lines and editor marks
+// instead of rendering them.
+type outputCollector struct {
+	mu    sync.Mutex
+	msgs  []runnerOutputMsg
+	marks []editorMark
+}
+
+func (c *outputCollector) Send(msg tea.Msg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch msg := msg.(type) {
+	case runnerOutputMsg:
+		c.msgs = append(c.msgs, msg)
+	case runnerEventMsg:
+		c.msgs = append(c.msgs, msg.outputLines()...)
+	case diagnosticsMsg:
+		c.marks = append(c.marks, msg.Marks...)
+	}
+}
+
+// firstLine returns the first failed case, or else the first non-empty
+// output line, for the summary table.
+func (c *outputCollector) firstLine() string {
+	first := ""
+	for _, msg := range c.msgs {
+		if l := strings.TrimSpace(msg.Line); l != "" {
+			if i := strings.IndexByte(l, '\n'); i >= 0 {
+				l = l[:i]
+			}
+			if strings.HasPrefix(l, "❌") {
+				return l
+			}
+			if first == "" {
+				first = l
+			}
+		}
+	}
+	return first
+}
+
+type validation struct {
+	ex            internal.Exercise
+	solutionErr   error
+	solutionOut   string
+	starterPassed bool
+}
+
+func (v validation) ok() bool {
+	return v.ex.Solution != "" && v.solutionErr == nil && !v.starterPassed
+}
+
+func validateExercise(ex internal.Exercise) validation {
+	v := validation{ex: ex}
+	if ex.Solution != "" {
+		var out outputCollector
+		v.solutionErr = runExercise(context.Background(), ex.Solution, projectFiles(ex, solutionEdits(ex)), ex, &out)
+		v.solutionOut = out.firstLine()
+	}
+	v.starterPassed = runExercise(context.Background(), ex.StarterCode, ex.Files, ex, &outputCollector{}) == nil
+	return v
+}
+
+// runValidate validates the exercises named by ids (or all of them), prints
+// a pass/fail table to w and returns the process exit code.
+func runValidate(exs []internal.Exercise, packErrs []error, ids []string, w io.Writer) int {
+	exitCode := 0
+	for _, err := range packErrs {
+		fmt.Fprintln(w, "❌ pack:", err)
+		exitCode = 1
+	}
+
+	selected := exs
+	if len(ids) > 0 {
+		byID := make(map[string]internal.Exercise, len(exs))
+		for _, ex := range exs {
+			byID[ex.ID] = ex
+		}
+		selected = nil
+		for _, id := range ids {
+			ex, ok := byID[id]
+			if !ok {
+				fmt.Fprintf(w, "❌ unknown exercise %q\n", id)
+				exitCode = 1
+				continue
+			}
+			selected = append(selected, ex)
+		}
+	}
+
+	if !runsESM(activeBackend) {
+		kept := selected[:0:0]
+		for _, ex := range selected {
+			if ex.ESM && !ex.TypeOnly {
+				fmt.Fprintf(w, "skipped %s: %v\n", ex.ID, errESMUnsupported)
+				continue
+			}
+			kept = append(kept, ex)
+		}
+		selected = kept
+	}
+
+	// Each run spawns tsc and node, so spread them over the available cores.
+	results := make([]validation, len(selected))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for n := 0; n < runtime.NumCPU(); n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = validateExercise(selected[i])
+			}
+		}()
+	}
+	for i := range selected {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tEXERCISE\tSOLUTION\tSTARTER\tDETAIL")
+	failed := 0
+	for _, v := range results {
+		mark, solution, starter, detail := "✅", "passes", "fails", ""
+		switch {
+		case v.ex.Solution == "":
+			solution, detail = "missing", "no reference solution"
+		case v.solutionErr != nil:
+			solution, detail = "FAILS", v.solutionOut
+		}
+		if v.starterPassed {
+			starter = "PASSES"
+			if detail == "" {
+				detail = "starter code already solves the koan"
+			}
+		}
+		if !v.ok() {
+			mark = "❌"
+			failed++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", mark, v.ex.ID, solution, starter, detail)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d/%d exercises OK\n", len(results)-failed, len(results))
+	if failed > 0 {
+		exitCode = 1
+	}
+	return exitCode
+}